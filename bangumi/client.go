@@ -8,8 +8,10 @@ import (
 	"github.com/bangumilite/bangumilite-component/model"
 	"github.com/go-resty/resty/v2"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type APIPath string
@@ -32,13 +34,28 @@ const (
 
 	MaxConcurrentGoroutines = 10
 
+	SubjectCommentsPath    = "/subject/%d/comments?page=%d"
+	DefaultMaxCommentPages = 20
+
 	APIPathGetSubject           APIPath = "/v0/subjects/%d"
 	APIPathGetSubjectCharacters APIPath = "/v0/subjects/%d/characters"
+	APIPathSearchSubjects       APIPath = "/v0/search/subjects"
+	APIPathGetEpisodes          APIPath = "/v0/episodes"
+
+	EpisodesPageSize = 200
 
 	ErrorGeneric APIError = "ErrorGeneric"
 	ErrorOAuth   APIError = "ErrorOAuth"
 )
 
+// CommentQuery controls when GetSubjectComments stops paging.
+type CommentQuery struct {
+	MaxCount int              // stop once this many comments are collected, 0 means no limit
+	MaxAge   time.Duration    // stop at the first comment older than this, 0 means no limit, comments of unknown age never stop
+	MaxPages int              // defaults to DefaultMaxCommentPages
+	Now      func() time.Time // clock used to resolve relative times, defaults to time.Now
+}
+
 type Client struct {
 	client *resty.Client
 }
@@ -86,6 +103,34 @@ func (c *Client) GetSubject(ctx context.Context, id int, opts ...RequestOption)
 	return &subject, nil
 }
 
+func (c *Client) SearchSubjects(ctx context.Context, search model.BangumiSearchRequest, limit int, offset int, opts ...RequestOption) (*model.BangumiSearchResponse, error) {
+	url := apiURL(APIPathSearchSubjects)
+	result := model.BangumiSearchResponse{}
+
+	req := c.client.R().
+		SetContext(ctx).
+		SetHeader("User-Agent", UserAgentHeader).
+		SetHeader("Content-Type", ContentTypeJSON).
+		SetQueryParam("limit", strconv.Itoa(limit)).
+		SetQueryParam("offset", strconv.Itoa(offset)).
+		SetBody(search).
+		SetResult(&result).
+		SetError(model.BangumiGenericErrorResponse{})
+
+	applyRequestOptions(req, opts...)
+
+	resp, err := req.Post(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsError() {
+		return nil, newAPIError(resp, url, ErrorGeneric)
+	}
+
+	return &result, nil
+}
+
 func (c *Client) GetSubjectCharacters(ctx context.Context, id int) ([]model.BangumiRelatedCharacter, error) {
 	url := apiURL(APIPathGetSubjectCharacters, id)
 	var characters []model.BangumiRelatedCharacter
@@ -109,6 +154,44 @@ func (c *Client) GetSubjectCharacters(ctx context.Context, id int) ([]model.Bang
 	return characters, nil
 }
 
+// GetSubjectEpisodes returns every main episode of the subject, paging through the API.
+func (c *Client) GetSubjectEpisodes(ctx context.Context, id int, opts ...RequestOption) ([]model.BangumiEpisode, error) {
+	url := apiURL(APIPathGetEpisodes)
+	var episodes []model.BangumiEpisode
+
+	for offset := 0; ; offset += EpisodesPageSize {
+		page := model.BangumiEpisodesResponse{}
+
+		req := c.client.R().
+			SetContext(ctx).
+			SetHeader("User-Agent", UserAgentHeader).
+			SetHeader("Content-Type", ContentTypeJSON).
+			SetQueryParam("subject_id", strconv.Itoa(id)).
+			SetQueryParam("type", strconv.Itoa(model.EpisodeTypeMain)).
+			SetQueryParam("limit", strconv.Itoa(EpisodesPageSize)).
+			SetQueryParam("offset", strconv.Itoa(offset)).
+			SetResult(&page).
+			SetError(model.BangumiGenericErrorResponse{})
+
+		applyRequestOptions(req, opts...)
+
+		resp, err := req.Get(url)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.IsError() {
+			return nil, newAPIError(resp, url, ErrorGeneric)
+		}
+
+		episodes = append(episodes, page.Data...)
+
+		if len(page.Data) == 0 || len(episodes) >= page.Total {
+			return episodes, nil
+		}
+	}
+}
+
 func (c *Client) RefreshAccessToken(ctx context.Context, token model.FirestoreBangumiToken) (*model.BangumiOAuthResponse, error) {
 	tokenResp := model.BangumiOAuthResponse{}
 	formData := map[string]string{
@@ -162,6 +245,50 @@ func (c *Client) GetHTML(ctx context.Context, path string) (*goquery.Document, e
 	return doc, nil
 }
 
+// GetSubjectComments pages through /subject/{id}/comments, newest first, until the query limits are reached
+// or a page has no comments.
+func (c *Client) GetSubjectComments(ctx context.Context, id int, query CommentQuery) ([]model.BangumiSubjectComment, error) {
+	now := time.Now
+	if query.Now != nil {
+		now = query.Now
+	}
+
+	maxPages := query.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxCommentPages
+	}
+
+	current := now()
+	var comments []model.BangumiSubjectComment
+
+	for page := 1; page <= maxPages; page++ {
+		doc, err := c.GetHTML(ctx, fmt.Sprintf(SubjectCommentsPath, id, page))
+		if err != nil {
+			return nil, err
+		}
+
+		parsed := ParseSubjectComments(doc, current)
+		if len(parsed) == 0 {
+			break
+		}
+
+		for _, comment := range parsed {
+			// a zero Time is an unknown age and does not stop paging
+			if query.MaxAge > 0 && !comment.Time.IsZero() && current.Sub(comment.Time) > query.MaxAge {
+				return comments, nil
+			}
+
+			comments = append(comments, comment)
+
+			if query.MaxCount > 0 && len(comments) >= query.MaxCount {
+				return comments, nil
+			}
+		}
+	}
+
+	return comments, nil
+}
+
 func apiURL(p APIPath, args ...interface{}) string {
 	return fmt.Sprintf(APIBaseURL+string(p), args...)
 }
@@ -2,6 +2,7 @@ package bangumi
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/bangumilite/bangumilite-component/model"
 	"github.com/jarcoal/httpmock"
@@ -9,6 +10,8 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"net/http"
+	"strings"
+	"time"
 )
 
 var _ = Describe("Bangumi API Unit Tests", func() {
@@ -214,4 +217,172 @@ var _ = Describe("Bangumi API Unit Tests", func() {
 			Expect(resp).To(BeNil())
 		})
 	})
+
+	Describe("SearchSubjects", func() {
+		It("posts the filter and returns the results", func() {
+			httpmock.RegisterResponder("POST", "https://api.bgm.tv/v0/search/subjects?limit=10&offset=0",
+				func(req *http.Request) (*http.Response, error) {
+					var body model.BangumiSearchRequest
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+					Expect(body.Filter.Tag).To(Equal([]string{"百合"}))
+
+					resp := httpmock.NewStringResponse(200, `
+						{
+							"total": 1,
+							"limit": 10,
+							"offset": 0,
+							"data": [{"id": 1, "name": "string", "name_cn": "string", "summary": "string"}]
+						}
+					`,
+					)
+					resp.Header.Add("Content-Type", "application/json")
+					return resp, nil
+				},
+			)
+
+			search := model.BangumiSearchRequest{
+				Filter: model.BangumiSearchFilter{Tag: []string{"百合"}},
+			}
+
+			resp, err := client.SearchSubjects(context.Background(), search, 10, 0)
+
+			Expect(err).To(BeNil())
+			Expect(resp.Total).To(Equal(1))
+			Expect(resp.Data[0].ID).To(Equal(1))
+		})
+
+		It("returns error if request returns error", func() {
+			httpmock.RegisterResponder("POST", "https://api.bgm.tv/v0/search/subjects?limit=10&offset=0",
+				func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(400, `{"title": "Bad Request", "description": "invalid filter"}`)
+					resp.Header.Add("Content-Type", "application/json")
+					return resp, nil
+				},
+			)
+
+			resp, err := client.SearchSubjects(context.Background(), model.BangumiSearchRequest{}, 10, 0)
+
+			Expect(err).ToNot(BeNil())
+			Expect(resp).To(BeNil())
+		})
+	})
+
+	Describe("GetSubjectEpisodes", func() {
+		It("pages through the episodes", func() {
+			httpmock.RegisterResponder("GET", "https://api.bgm.tv/v0/episodes?limit=200&offset=0&subject_id=1&type=0",
+				httpmock.NewStringResponder(200, `{"total": 2, "limit": 1, "offset": 0, "data": [{"id": 10, "type": 0, "ep": 1, "sort": 1, "airdate": "2025-04-05"}]}`).
+					HeaderAdd(http.Header{"Content-Type": []string{"application/json"}}),
+			)
+			httpmock.RegisterResponder("GET", "https://api.bgm.tv/v0/episodes?limit=200&offset=200&subject_id=1&type=0",
+				httpmock.NewStringResponder(200, `{"total": 2, "limit": 1, "offset": 1, "data": [{"id": 11, "type": 0, "ep": 2, "sort": 2, "airdate": "2025-04-12"}]}`).
+					HeaderAdd(http.Header{"Content-Type": []string{"application/json"}}),
+			)
+
+			got, err := client.GetSubjectEpisodes(context.Background(), 1)
+
+			Expect(err).To(BeNil())
+			Expect(got).To(HaveLen(2))
+			Expect(got[1].Airdate).To(Equal("2025-04-12"))
+		})
+
+		It("returns error if request returns error", func() {
+			httpmock.RegisterResponder("GET", "https://api.bgm.tv/v0/episodes?limit=200&offset=0&subject_id=1&type=0",
+				httpmock.NewStringResponder(404, `{"title": "Not Found", "description": "subject not found"}`).
+					HeaderAdd(http.Header{"Content-Type": []string{"application/json"}}),
+			)
+
+			got, err := client.GetSubjectEpisodes(context.Background(), 1)
+
+			Expect(err).ToNot(BeNil())
+			Expect(got).To(BeNil())
+		})
+	})
+
+	Describe("GetSubjectComments", func() {
+		now := time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC)
+
+		commentItem := func(user string, ago string) string {
+			return fmt.Sprintf(`
+				<div class="item clearit">
+					<div class="text">
+						<a href="/user/%s" class="l">%s</a> <small class="grey">@ %s</small>
+						<p>comment</p>
+					</div>
+				</div>`, user, user, ago)
+		}
+
+		registerPage := func(page int, items ...string) {
+			httpmock.RegisterResponder("GET", fmt.Sprintf("https://bangumi.tv/subject/1/comments?page=%d", page),
+				httpmock.NewStringResponder(200, `<div id="comment_box">`+strings.Join(items, "")+`</div>`),
+			)
+		}
+
+		It("pages until a page has no comments", func() {
+			registerPage(1, commentItem("a", "1小时前"), commentItem("b", "2小时前"))
+			registerPage(2, commentItem("c", "3小时前"))
+			registerPage(3)
+
+			got, err := client.GetSubjectComments(context.Background(), 1, CommentQuery{
+				Now: func() time.Time { return now },
+			})
+
+			Expect(err).To(BeNil())
+			Expect(got).To(HaveLen(3))
+			Expect(httpmock.GetTotalCallCount()).To(Equal(3))
+		})
+
+		It("stops at the max count", func() {
+			registerPage(1, commentItem("a", "1小时前"), commentItem("b", "2小时前"))
+			registerPage(2, commentItem("c", "3小时前"))
+
+			got, err := client.GetSubjectComments(context.Background(), 1, CommentQuery{
+				MaxCount: 2,
+				Now:      func() time.Time { return now },
+			})
+
+			Expect(err).To(BeNil())
+			Expect(got).To(HaveLen(2))
+			Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		})
+
+		It("stops at the first comment older than max age", func() {
+			registerPage(1, commentItem("a", "1小时前"), commentItem("b", "2天前"))
+			registerPage(2, commentItem("c", "3天前"))
+
+			got, err := client.GetSubjectComments(context.Background(), 1, CommentQuery{
+				MaxAge: 24 * time.Hour,
+				Now:    func() time.Time { return now },
+			})
+
+			Expect(err).To(BeNil())
+			Expect(got).To(HaveLen(1))
+			Expect(got[0].UserID).To(Equal("a"))
+		})
+
+		It("does not stop at comments of unknown age", func() {
+			registerPage(1, commentItem("a", "1小时前"), commentItem("b", "上周"))
+			registerPage(2, commentItem("c", "2小时前"), commentItem("d", "2天前"))
+
+			got, err := client.GetSubjectComments(context.Background(), 1, CommentQuery{
+				MaxAge: 24 * time.Hour,
+				Now:    func() time.Time { return now },
+			})
+
+			Expect(err).To(BeNil())
+			Expect(got).To(HaveLen(3))
+			Expect(got[1].Time.IsZero()).To(BeTrue())
+			Expect(got[2].UserID).To(Equal("c"))
+		})
+
+		It("returns error if the page request fails", func() {
+			httpmock.RegisterResponder("GET", "https://bangumi.tv/subject/1/comments?page=1", httpmock.NewStringResponder(404, ""))
+
+			got, err := client.GetSubjectComments(context.Background(), 1, CommentQuery{})
+
+			Expect(err).ToNot(BeNil())
+			Expect(got).To(BeNil())
+		})
+	})
 })
@@ -0,0 +1,61 @@
+package bangumi
+
+import (
+	"context"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/bangumilite/bangumilite-component/model"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.11.2 -o bangumifakes/fake_subject_fetcher.go . SubjectFetcher
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.11.2 -o bangumifakes/fake_character_fetcher.go . CharacterFetcher
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.11.2 -o bangumifakes/fake_html_fetcher.go . HTMLFetcher
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.11.2 -o bangumifakes/fake_token_refresher.go . TokenRefresher
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.11.2 -o bangumifakes/fake_comment_fetcher.go . CommentFetcher
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.11.2 -o bangumifakes/fake_subject_searcher.go . SubjectSearcher
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6@v6.11.2 -o bangumifakes/fake_episode_fetcher.go . EpisodeFetcher
+
+// SubjectFetcher fetches subjects from the Bangumi API.
+type SubjectFetcher interface {
+	GetSubject(ctx context.Context, id int, opts ...RequestOption) (*model.BangumiSubject, error)
+	GetSubjects(ctx context.Context, ids []int, opts ...RequestOption) ([]model.BangumiSubject, error)
+}
+
+// CharacterFetcher fetches the characters related to a subject.
+type CharacterFetcher interface {
+	GetSubjectCharacters(ctx context.Context, id int) ([]model.BangumiRelatedCharacter, error)
+}
+
+// HTMLFetcher fetches and parses Bangumi web pages.
+type HTMLFetcher interface {
+	GetHTML(ctx context.Context, path string) (*goquery.Document, error)
+}
+
+// TokenRefresher exchanges a refresh token for a new access token.
+type TokenRefresher interface {
+	RefreshAccessToken(ctx context.Context, token model.FirestoreBangumiToken) (*model.BangumiOAuthResponse, error)
+}
+
+// CommentFetcher pages through the short comments of a subject.
+type CommentFetcher interface {
+	GetSubjectComments(ctx context.Context, id int, query CommentQuery) ([]model.BangumiSubjectComment, error)
+}
+
+// SubjectSearcher searches subjects with the Bangumi API search filters.
+type SubjectSearcher interface {
+	SearchSubjects(ctx context.Context, search model.BangumiSearchRequest, limit int, offset int, opts ...RequestOption) (*model.BangumiSearchResponse, error)
+}
+
+// EpisodeFetcher fetches the episodes of a subject.
+type EpisodeFetcher interface {
+	GetSubjectEpisodes(ctx context.Context, id int, opts ...RequestOption) ([]model.BangumiEpisode, error)
+}
+
+var (
+	_ SubjectFetcher   = (*Client)(nil)
+	_ CharacterFetcher = (*Client)(nil)
+	_ HTMLFetcher      = (*Client)(nil)
+	_ TokenRefresher   = (*Client)(nil)
+	_ CommentFetcher   = (*Client)(nil)
+	_ SubjectSearcher  = (*Client)(nil)
+	_ EpisodeFetcher   = (*Client)(nil)
+)
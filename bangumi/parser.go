@@ -4,13 +4,36 @@ import (
 	"errors"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
+	"github.com/bangumilite/bangumilite-component/model"
 	"github.com/bangumilite/bangumilite-component/utils"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var BackgroundImageUrlRegex = regexp.MustCompile(`url\(["']?(//[^\)"']+)["']?\)`)
+var StarsRegex = regexp.MustCompile(`stars(\d+)`)
+var RelativeTimeRegex = regexp.MustCompile(`(\d+)\s*(年|月|周|天|小时|分钟|秒)`)
+
+// BangumiLocation is the timezone absolute timestamps on bangumi.tv are rendered in.
+var BangumiLocation = time.FixedZone("CST", 8*60*60)
+
+var relativeTimeUnits = map[string]time.Duration{
+	"年":  365 * 24 * time.Hour,
+	"月":  30 * 24 * time.Hour,
+	"周":  7 * 24 * time.Hour,
+	"天":  24 * time.Hour,
+	"小时": time.Hour,
+	"分钟": time.Minute,
+	"秒":  time.Second,
+}
+
+var absoluteTimeLayouts = []string{
+	"2006-1-2 15:04",
+	"2006-1-2 15:04:05",
+	"2006-1-2",
+}
 
 // ParseSubjectIDs extracts unique subject IDs from the given document.
 func ParseSubjectIDs(doc *goquery.Document) []int {
@@ -108,3 +131,95 @@ func GetID(href string) (*int, error) {
 
 	return &id, nil
 }
+
+// ParseSubjectComments extracts the comments from a /subject/{id}/comments page.
+// Relative timestamps such as "3小时前" are resolved against now, comments whose timestamp
+// cannot be parsed are kept with a zero Time.
+func ParseSubjectComments(doc *goquery.Document, now time.Time) []model.BangumiSubjectComment {
+	var comments []model.BangumiSubjectComment
+
+	doc.Find("#comment_box .item").Each(func(i int, s *goquery.Selection) {
+		userLink := s.Find("a.l[href^='/user/']").First()
+		href, _ := userLink.Attr("href")
+
+		if len(href) == 0 {
+			return
+		}
+
+		var comment model.BangumiSubjectComment
+		comment.UserID = strings.TrimPrefix(href, "/user/")
+		comment.UserName = strings.TrimSpace(userLink.Text())
+
+		if style, ok := s.Find("span.avatarNeue").Attr("style"); ok {
+			if avatar := ParseImageURLFromStyle(style); avatar != nil {
+				comment.Avatar = *avatar
+			}
+		}
+
+		if class, ok := s.Find("span.starlight").Attr("class"); ok {
+			if res := StarsRegex.FindStringSubmatch(class); len(res) > 1 {
+				comment.Rating, _ = strconv.Atoi(res[1])
+			}
+		}
+
+		// the grey hint reads either "@ 3小时前" or "看过 @ 2024-6-12 21:33"
+		hint := strings.TrimSpace(s.Find("small.grey").First().Text())
+		status, timestamp, found := strings.Cut(hint, "@")
+		if !found {
+			timestamp = status
+			status = ""
+		}
+		comment.Status = strings.TrimSpace(status)
+
+		// an unknown time format keeps the comment with a zero Time rather than dropping it
+		if t, err := ParseCommentTime(strings.TrimSpace(timestamp), now); err == nil {
+			comment.Time = *t
+		}
+
+		comment.Text = strings.TrimSpace(s.Find("p").First().Text())
+
+		comments = append(comments, comment)
+	})
+
+	return comments
+}
+
+// ParseCommentTime parses either a relative time such as "1天3小时前" and "刚刚", resolved against now,
+// or an absolute time such as "2024-6-12 21:33" rendered in BangumiLocation.
+func ParseCommentTime(s string, now time.Time) (*time.Time, error) {
+	if len(s) == 0 {
+		return nil, errors.New("comment time is empty")
+	}
+
+	if s == "刚刚" {
+		return &now, nil
+	}
+
+	if strings.HasSuffix(s, "前") {
+		matches := RelativeTimeRegex.FindAllStringSubmatch(s, -1)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("unable to parse relative time %s", s)
+		}
+
+		var ago time.Duration
+		for _, match := range matches {
+			n, err := strconv.Atoi(match[1])
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse relative time %s", s)
+			}
+			ago += time.Duration(n) * relativeTimeUnits[match[2]]
+		}
+
+		t := now.Add(-ago)
+		return &t, nil
+	}
+
+	for _, layout := range absoluteTimeLayouts {
+		t, err := time.ParseInLocation(layout, s, BangumiLocation)
+		if err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unable to parse comment time %s", s)
+}
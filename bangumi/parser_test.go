@@ -0,0 +1,104 @@
+package bangumi
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strings"
+	"time"
+)
+
+const commentsPage = `
+<div id="comment_box">
+	<div class="item clearit">
+		<a href="/user/alice" class="avatar"><span class="avatarNeue avatarSize32 ll" style="background-image:url('//lain.bgm.tv/pic/user/s/000/00/00/1.jpg')"></span></a>
+		<div class="text_main_even">
+			<div class="text">
+				<a href="/user/alice" class="l">Alice</a> <small class="grey">@ 3小时前</small>
+				<span class="starstop-s"><span class="starlight stars8"></span></span>
+				<p>很好看</p>
+			</div>
+		</div>
+	</div>
+	<div class="item clearit">
+		<a href="/user/bob" class="avatar"><span class="avatarNeue avatarSize32 ll" style="background-image:url('//lain.bgm.tv/pic/user/s/000/00/00/2.jpg')"></span></a>
+		<div class="text_main_even">
+			<div class="text">
+				<a href="/user/bob" class="l">Bob</a> <small class="grey">看过 @ 2024-6-12 21:33</small>
+				<p>一般</p>
+			</div>
+		</div>
+	</div>
+</div>
+`
+
+var _ = Describe("bangumi parser unit tests", func() {
+	now := time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	Describe("ParseSubjectComments", func() {
+		It("parses user, avatar, rating, status, time and text", func() {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(commentsPage))
+			Expect(err).To(BeNil())
+
+			got := ParseSubjectComments(doc, now)
+
+			Expect(got).To(HaveLen(2))
+
+			Expect(got[0].UserID).To(Equal("alice"))
+			Expect(got[0].UserName).To(Equal("Alice"))
+			Expect(got[0].Avatar).To(Equal("https://lain.bgm.tv/pic/user/s/000/00/00/1.jpg"))
+			Expect(got[0].Rating).To(Equal(8))
+			Expect(got[0].Status).To(BeEmpty())
+			Expect(got[0].Time).To(Equal(now.Add(-3 * time.Hour)))
+			Expect(got[0].Text).To(Equal("很好看"))
+
+			Expect(got[1].UserID).To(Equal("bob"))
+			Expect(got[1].Rating).To(Equal(0))
+			Expect(got[1].Status).To(Equal("看过"))
+			Expect(got[1].Time.Equal(time.Date(2024, 6, 12, 13, 33, 0, 0, time.UTC))).To(BeTrue())
+		})
+
+		It("keeps comments whose time cannot be parsed with a zero time", func() {
+			page := strings.Replace(commentsPage, "@ 3小时前", "@ 上周", 1)
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+			Expect(err).To(BeNil())
+
+			got := ParseSubjectComments(doc, now)
+
+			Expect(got).To(HaveLen(2))
+			Expect(got[0].UserID).To(Equal("alice"))
+			Expect(got[0].Time.IsZero()).To(BeTrue())
+			Expect(got[0].Text).To(Equal("很好看"))
+		})
+
+		It("returns no comments for an empty page", func() {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<div id="comment_box"></div>`))
+			Expect(err).To(BeNil())
+
+			Expect(ParseSubjectComments(doc, now)).To(BeEmpty())
+		})
+	})
+
+	Describe("ParseCommentTime", func() {
+		It("resolves combined relative units", func() {
+			got, err := ParseCommentTime("1天3小时前", now)
+
+			Expect(err).To(BeNil())
+			Expect(*got).To(Equal(now.Add(-27 * time.Hour)))
+		})
+
+		It("resolves just now", func() {
+			got, err := ParseCommentTime("刚刚", now)
+
+			Expect(err).To(BeNil())
+			Expect(*got).To(Equal(now))
+		})
+
+		It("returns error for unknown formats", func() {
+			got, err := ParseCommentTime("yesterday", now)
+
+			Expect(err).ToNot(BeNil())
+			Expect(got).To(BeNil())
+		})
+	})
+})
@@ -54,6 +54,26 @@ func Convert(from ImagePath, to ImagePath, src string) (*string, error) {
 	return &imageURL, nil
 }
 
+// SmallVariant returns the small variant of a lain.bgm.tv cover or character image.
+// The src is returned unchanged if it does not match any known image path.
+func SmallVariant(src string) string {
+	conversions := map[ImagePath][]ImagePath{
+		SubjectSmall:   {SubjectLarge, SubjectMedium, SubjectGrid},
+		CharacterSmall: {CharacterLarge, CharacterMedium, CharacterGrid},
+	}
+
+	for to, froms := range conversions {
+		for _, from := range froms {
+			imageURL, err := Convert(from, to, src)
+			if err == nil {
+				return *imageURL
+			}
+		}
+	}
+
+	return src
+}
+
 func GetVoiceActorsFromCharacters(characters []model.BangumiRelatedCharacter) []model.BangumiPerson {
 	mp := make(map[int]bool)
 	var actors []model.BangumiPerson
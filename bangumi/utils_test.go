@@ -0,0 +1,28 @@
+package bangumi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("bangumi utils unit tests", func() {
+	Describe("SmallVariant", func() {
+		It("converts a large cover to the small variant", func() {
+			got := SmallVariant("https://lain.bgm.tv/pic/cover/l/c4/1.jpg")
+
+			Expect(got).To(Equal("https://lain.bgm.tv/pic/cover/s/c4/1.jpg"))
+		})
+
+		It("converts a medium character image to the small variant", func() {
+			got := SmallVariant("//lain.bgm.tv/pic/crt/m/c4/1.jpg")
+
+			Expect(got).To(Equal("//lain.bgm.tv/pic/crt/s/c4/1.jpg"))
+		})
+
+		It("returns the src unchanged if it is not a bangumi image", func() {
+			got := SmallVariant("https://cdn.example.com/1.jpg")
+
+			Expect(got).To(Equal("https://cdn.example.com/1.jpg"))
+		})
+	})
+})
@@ -0,0 +1,173 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/model"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const DefaultLimit = 20
+
+// TitleData is passed to the title template of each rule.
+type TitleData struct {
+	Count int
+	Year  int
+	Month int
+	Now   time.Time
+}
+
+type section struct {
+	rule  Rule
+	title *template.Template
+}
+
+// Builder evaluates a rule set over a subject corpus to produce discovery sections.
+type Builder struct {
+	sections []section
+	now      func() time.Time
+}
+
+func NewBuilder(rs RuleSet) (*Builder, error) {
+	if len(rs.Sections) == 0 {
+		return nil, errors.New("discovery rule set has no sections")
+	}
+
+	sections := make([]section, len(rs.Sections))
+	for i, rule := range rs.Sections {
+		if len(rule.Title) == 0 {
+			return nil, fmt.Errorf("section %d: title cannot be empty", i)
+		}
+
+		title, err := template.New(fmt.Sprintf("section-%d", i)).Option("missingkey=error").Parse(rule.Title)
+		if err != nil {
+			return nil, fmt.Errorf("section %d: invalid title template: %w", i, err)
+		}
+
+		if _, err := sortKey(rule.Sort); err != nil {
+			return nil, fmt.Errorf("section %d: %w", i, err)
+		}
+
+		if err := rule.Filter.compile(); err != nil {
+			return nil, fmt.Errorf("section %d: %w", i, err)
+		}
+
+		sections[i] = section{rule: rule, title: title}
+	}
+
+	return &Builder{
+		sections: sections,
+		now:      time.Now,
+	}, nil
+}
+
+// SetClock overrides the clock used by relative air date filters and titles.
+func (b *Builder) SetClock(now func() time.Time) {
+	b.now = now
+}
+
+// Build evaluates the sections in order. A subject is used by the first section it qualifies for
+// and skipped by the following ones, sections left empty are omitted.
+func (b *Builder) Build(subjects []model.BangumiSubject) ([]model.FirestoreDiscoverySubject, error) {
+	now := b.now()
+	used := make(map[int]bool)
+
+	var res []model.FirestoreDiscoverySubject
+	for i, s := range b.sections {
+		var candidates []model.BangumiSubject
+		for _, subject := range subjects {
+			if !used[subject.ID] && s.rule.Filter.matches(subject, now) {
+				candidates = append(candidates, subject)
+			}
+		}
+
+		sortSubjects(candidates, s.rule.Sort)
+
+		limit := s.rule.Limit
+		if limit <= 0 {
+			limit = DefaultLimit
+		}
+		if len(candidates) > limit {
+			candidates = candidates[:limit]
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		data := make([]model.FirestoreSubject, len(candidates))
+		for j, subject := range candidates {
+			used[subject.ID] = true
+			data[j] = subject.ToFirestoreSubject()
+		}
+
+		var title strings.Builder
+		err := s.title.Execute(&title, TitleData{
+			Count: len(data),
+			Year:  now.Year(),
+			Month: int(now.Month()),
+			Now:   now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("section %d: failed to render title: %w", i, err)
+		}
+
+		res = append(res, model.FirestoreDiscoverySubject{
+			Title: title.String(),
+			Data:  data,
+		})
+	}
+
+	return res, nil
+}
+
+// less reports whether a should be listed before b in the natural order of the key.
+type less func(a, b model.BangumiSubject) bool
+
+func sortKey(key SortKey) (less, error) {
+	reverse := strings.HasPrefix(string(key), ReversePrefix)
+	name := SortKey(strings.TrimPrefix(string(key), ReversePrefix))
+
+	var fn less
+	switch name {
+	case SortByScore, "":
+		fn = func(a, b model.BangumiSubject) bool { return a.Rating.Score > b.Rating.Score }
+	case SortByRank:
+		// unranked subjects always go last, so the prefix only reverses the ranked ones
+		return func(a, b model.BangumiSubject) bool {
+			if a.Rating.Rank == 0 || b.Rating.Rank == 0 {
+				return b.Rating.Rank == 0 && a.Rating.Rank != 0
+			}
+			if reverse {
+				return a.Rating.Rank > b.Rating.Rank
+			}
+			return a.Rating.Rank < b.Rating.Rank
+		}, nil
+	case SortByCollection:
+		fn = func(a, b model.BangumiSubject) bool { return a.Collection.Total() > b.Collection.Total() }
+	case SortByAirDate:
+		fn = func(a, b model.BangumiSubject) bool { return a.Date > b.Date }
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", key)
+	}
+
+	if reverse {
+		return func(a, b model.BangumiSubject) bool { return fn(b, a) }, nil
+	}
+
+	return fn, nil
+}
+
+func sortSubjects(subjects []model.BangumiSubject, key SortKey) {
+	fn, err := sortKey(key)
+	if err != nil {
+		return
+	}
+
+	sort.SliceStable(subjects, func(i, j int) bool {
+		return fn(subjects[i], subjects[j])
+	})
+}
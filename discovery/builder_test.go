@@ -0,0 +1,144 @@
+package discovery
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+const yamlRules = `
+sections:
+  - title: "{{.Year}}年高分百合"
+    filter:
+      types: [2]
+      tags: ["百合"]
+      min_score: 7.5
+    sort: score
+    limit: 2
+  - title: "人气动画"
+    filter:
+      types: [2]
+      min_collection: 1000
+    sort: collection
+`
+
+const jsonRules = `{
+  "sections": [
+    {"title": "近期新番", "filter": {"aired_within_days": 30}, "sort": "air_date"}
+  ]
+}`
+
+var corpus = []model.BangumiSubject{
+	{ID: 1, Type: 2, Date: "2025-03-01", Tags: model.BangumiTags{{Name: "百合"}}, Rating: model.BangumiRating{Score: 8.1, Rank: 100}, Collection: model.BangumiCollection{Collect: 5000}},
+	{ID: 2, Type: 2, Date: "2024-01-01", Tags: model.BangumiTags{{Name: "百合"}}, Rating: model.BangumiRating{Score: 7.6, Rank: 400}, Collection: model.BangumiCollection{Collect: 800}},
+	{ID: 3, Type: 2, Date: "2025-03-20", Tags: model.BangumiTags{{Name: "百合"}}, Rating: model.BangumiRating{Score: 7.0}, Collection: model.BangumiCollection{Collect: 3000}},
+	{ID: 4, Type: 1, Date: "2025-03-25", Rating: model.BangumiRating{Score: 9.0}, Collection: model.BangumiCollection{Collect: 9000}},
+	{ID: 5, Type: 2, Tags: model.BangumiTags{{Name: "后宫"}}, Rating: model.BangumiRating{Score: 6.0}, Collection: model.BangumiCollection{Collect: 2000}},
+}
+
+var _ = Describe("discovery builder unit tests", func() {
+	now := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	build := func(rules string) []model.FirestoreDiscoverySubject {
+		rs, err := ParseRuleSet([]byte(rules))
+		Expect(err).To(BeNil())
+
+		builder, err := NewBuilder(*rs)
+		Expect(err).To(BeNil())
+		builder.SetClock(func() time.Time { return now })
+
+		sections, err := builder.Build(corpus)
+		Expect(err).To(BeNil())
+
+		return sections
+	}
+
+	ids := func(section model.FirestoreDiscoverySubject) []int {
+		var res []int
+		for _, s := range section.Data {
+			res = append(res, s.ID)
+		}
+		return res
+	}
+
+	It("builds sections from YAML rules and de-duplicates across sections", func() {
+		sections := build(yamlRules)
+
+		Expect(sections).To(HaveLen(2))
+		Expect(sections[0].Title).To(Equal("2025年高分百合"))
+		Expect(ids(sections[0])).To(Equal([]int{1, 2}))
+		Expect(sections[1].Title).To(Equal("人气动画"))
+		Expect(ids(sections[1])).To(Equal([]int{3, 5}))
+	})
+
+	It("builds sections from JSON rules with relative air dates", func() {
+		sections := build(jsonRules)
+
+		Expect(sections).To(HaveLen(1))
+		Expect(ids(sections[0])).To(Equal([]int{4, 3}))
+	})
+
+	It("reverses the sort order with the prefix", func() {
+		sections := build(`
+sections:
+  - title: "低分"
+    filter: {types: [2]}
+    sort: "-score"
+    limit: 1
+`)
+
+		Expect(ids(sections[0])).To(Equal([]int{5}))
+	})
+
+	It("keeps unranked subjects last in reverse rank order", func() {
+		sections := build(`
+sections:
+  - title: "排名"
+    filter: {types: [2]}
+    sort: "-rank"
+`)
+
+		Expect(ids(sections[0])).To(Equal([]int{2, 1, 3, 5}))
+	})
+
+	It("applies the query expression of a filter", func() {
+		sections := build(`
+sections:
+  - title: "不含后宫"
+    filter:
+      query: "type:anime -tag:后宫 collect>=1000"
+    sort: rank
+`)
+
+		Expect(ids(sections[0])).To(Equal([]int{1, 3}))
+	})
+
+	It("rejects invalid query expressions", func() {
+		_, err := NewBuilder(RuleSet{Sections: []Rule{{Title: "x", Filter: Filter{Query: "score>>7"}}}})
+
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("rejects unknown sort keys", func() {
+		_, err := NewBuilder(RuleSet{Sections: []Rule{{Title: "x", Sort: "popularity"}}})
+
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("rejects invalid title templates", func() {
+		_, err := NewBuilder(RuleSet{Sections: []Rule{{Title: "{{.Year"}}})
+
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("rejects unknown fields", func() {
+		_, err := ParseRuleSet([]byte(`
+sections:
+  - title: "x"
+    filter: {min_scroe: 7}
+`))
+
+		Expect(err).ToNot(BeNil())
+	})
+})
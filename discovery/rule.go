@@ -0,0 +1,200 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/bangumilite/bangumilite-component/query"
+	"gopkg.in/yaml.v3"
+	"os"
+	"time"
+)
+
+type SortKey string
+
+const (
+	SortByScore      SortKey = "score"
+	SortByRank       SortKey = "rank"
+	SortByCollection SortKey = "collection"
+	SortByAirDate    SortKey = "air_date"
+
+	// ReversePrefix reverses the natural order of a sort key, e.g. "-rank" lists the worst ranked first.
+	ReversePrefix = "-"
+)
+
+// RuleSet is the editor facing definition of a discovery page.
+type RuleSet struct {
+	Sections []Rule `json:"sections" yaml:"sections"`
+}
+
+// Rule declares how one discovery section is built.
+type Rule struct {
+	// Title is a text/template rendered with TitleData, e.g. "{{.Year}}年高分动画".
+	Title  string  `json:"title" yaml:"title"`
+	Filter Filter  `json:"filter" yaml:"filter"`
+	Sort   SortKey `json:"sort" yaml:"sort"`
+	Limit  int     `json:"limit" yaml:"limit"`
+}
+
+// Filter lists the conditions a subject must meet to appear in a section. Unset fields are ignored.
+type Filter struct {
+	Types       []int    `json:"types,omitempty" yaml:"types,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	ExcludeTags []string `json:"exclude_tags,omitempty" yaml:"exclude_tags,omitempty"`
+
+	MinScore      *float64 `json:"min_score,omitempty" yaml:"min_score,omitempty"`
+	MaxScore      *float64 `json:"max_score,omitempty" yaml:"max_score,omitempty"`
+	MinRank       *int     `json:"min_rank,omitempty" yaml:"min_rank,omitempty"`
+	MaxRank       *int     `json:"max_rank,omitempty" yaml:"max_rank,omitempty"`
+	MinCollection *int     `json:"min_collection,omitempty" yaml:"min_collection,omitempty"`
+	MaxCollection *int     `json:"max_collection,omitempty" yaml:"max_collection,omitempty"`
+
+	// AirDateFrom and AirDateTo are inclusive "2006-01-02" dates.
+	AirDateFrom string `json:"air_date_from,omitempty" yaml:"air_date_from,omitempty"`
+	AirDateTo   string `json:"air_date_to,omitempty" yaml:"air_date_to,omitempty"`
+	// AiredWithinDays keeps subjects aired in the last N days relative to the build time.
+	AiredWithinDays int `json:"aired_within_days,omitempty" yaml:"aired_within_days,omitempty"`
+
+	// Query is a query expression, e.g. "type:anime score>=7.5 -tag:后宫", combined with the other conditions.
+	Query string `json:"query,omitempty" yaml:"query,omitempty"`
+
+	query *query.Query
+}
+
+// ParseRuleSet decodes a rule set from JSON or YAML.
+func ParseRuleSet(data []byte) (*RuleSet, error) {
+	var rs RuleSet
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&rs); err != nil {
+			return nil, fmt.Errorf("invalid discovery rules: %w", err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(trimmed))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&rs); err != nil {
+			return nil, fmt.Errorf("invalid discovery rules: %w", err)
+		}
+	}
+
+	return &rs, nil
+}
+
+// LoadRuleSet reads a JSON or YAML rule set from a file.
+func LoadRuleSet(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRuleSet(data)
+}
+
+func (f Filter) matches(subject model.BangumiSubject, now time.Time) bool {
+	if f.query != nil && !f.query.Match(subject) {
+		return false
+	}
+
+	if len(f.Types) > 0 && !contains(f.Types, subject.Type) {
+		return false
+	}
+
+	names := make([]string, len(subject.Tags))
+	for i, tag := range subject.Tags {
+		names[i] = tag.Name
+	}
+
+	for _, tag := range f.Tags {
+		if !contains(names, tag) {
+			return false
+		}
+	}
+
+	for _, tag := range f.ExcludeTags {
+		if contains(names, tag) {
+			return false
+		}
+	}
+
+	score := subject.Rating.Score
+	if f.MinScore != nil && score < *f.MinScore {
+		return false
+	}
+	if f.MaxScore != nil && score > *f.MaxScore {
+		return false
+	}
+
+	// unranked subjects have rank 0 and never satisfy a rank condition
+	rank := subject.Rating.Rank
+	if (f.MinRank != nil || f.MaxRank != nil) && rank == 0 {
+		return false
+	}
+	if f.MinRank != nil && rank < *f.MinRank {
+		return false
+	}
+	if f.MaxRank != nil && rank > *f.MaxRank {
+		return false
+	}
+
+	collection := subject.Collection.Total()
+	if f.MinCollection != nil && collection < *f.MinCollection {
+		return false
+	}
+	if f.MaxCollection != nil && collection > *f.MaxCollection {
+		return false
+	}
+
+	if len(f.AirDateFrom) > 0 || len(f.AirDateTo) > 0 || f.AiredWithinDays > 0 {
+		airDate, ok := subject.AirDate()
+		if !ok {
+			return false
+		}
+
+		if from, err := time.Parse(time.DateOnly, f.AirDateFrom); err == nil && airDate.Before(from) {
+			return false
+		}
+		if to, err := time.Parse(time.DateOnly, f.AirDateTo); err == nil && airDate.After(to) {
+			return false
+		}
+		if f.AiredWithinDays > 0 && (airDate.Before(now.AddDate(0, 0, -f.AiredWithinDays)) || airDate.After(now)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compile validates the filter and parses its query expression.
+func (f *Filter) compile() error {
+	if len(f.Query) > 0 {
+		q, err := query.Parse(f.Query)
+		if err != nil {
+			return err
+		}
+		f.query = q
+	}
+
+	for _, date := range []string{f.AirDateFrom, f.AirDateTo} {
+		if len(date) == 0 {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return fmt.Errorf("invalid air date %q, expected format 2006-01-02", date)
+		}
+	}
+
+	return nil
+}
+
+func contains[T comparable](elems []T, v T) bool {
+	for _, e := range elems {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
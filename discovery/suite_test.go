@@ -0,0 +1,12 @@
+package discovery
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestDiscovery(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "discovery test suite")
+}
@@ -6,8 +6,9 @@ import (
 	"errors"
 	"github.com/bangumilite/bangumilite-component/mailer"
 	"github.com/bangumilite/bangumilite-component/model"
-	"google.golang.org/api/option"
-	"os"
+	"github.com/sirupsen/logrus"
+	"strconv"
+	"time"
 )
 
 const (
@@ -21,8 +22,12 @@ const (
 
 	MonoCollectionKey = "mono"
 
+	TrendingCollectionKey = "trending"
+
 	DiscoveryCollectionKey = "discovery"
 
+	RelatedCollectionKey = "related"
+
 	MailgunDocumentKey = "mailgun"
 
 	BangumiAccessTokenKey  = "access_token"
@@ -34,38 +39,38 @@ const (
 var ErrDocumentDoesNotExist = errors.New("document does not exist")
 
 type Client struct {
-	fs *firestore.Client
+	store store
+
+	writer         string
+	historyLimit   int
+	shardThreshold int
+	leaseTTL       time.Duration
+	leaseClockSkew time.Duration
+
+	dryRun            bool
+	dryRunDir         string
+	dryRunTokenWrites bool
+	logger            logrus.FieldLogger
 }
 
-func New(ctx context.Context) (*Client, error) {
-	var fs *firestore.Client
-	var err error
-
-	env := os.Getenv(model.RunningEnvironment)
-	switch env {
-	case string(model.Production):
-		fs, err = firestore.NewClient(ctx, FirebaseProjectID)
-	default:
-		fs, err = firestore.NewClient(ctx, FirebaseProjectID, option.WithCredentialsFile("service_account.json"))
-	}
+// New connects to Firestore, see the Option functions for the defaults.
+func New(ctx context.Context, opts ...Option) (*Client, error) {
+	cfg := newConfig(opts...)
 
+	fs, err := cfg.newFirestoreClient(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{
-		fs: fs,
-	}, nil
+	return cfg.newClient(&firestoreStore{fs: fs}), nil
 }
 
 func (c *Client) Close() error {
-	return c.fs.Close()
+	return c.store.close()
 }
 
 func (c *Client) GetBangumiToken(ctx context.Context) (*model.FirestoreBangumiToken, error) {
-	docRef := c.fs.Collection(TokenCollectionKey).Doc(TokenCollectionBangumiDocKey)
-
-	data, err := getDocument[model.FirestoreBangumiToken](ctx, docRef)
+	data, err := getDocument[model.FirestoreBangumiToken](ctx, c, DocumentPath(TokenCollectionKey, TokenCollectionBangumiDocKey))
 	if err != nil {
 		return nil, err
 	}
@@ -74,9 +79,7 @@ func (c *Client) GetBangumiToken(ctx context.Context) (*model.FirestoreBangumiTo
 }
 
 func (c *Client) GetMailgunConfig(ctx context.Context) (*mailer.MailgunConfig, error) {
-	docRef := c.fs.Collection(TokenCollectionKey).Doc(MailgunDocumentKey)
-
-	data, err := getDocument[mailer.MailgunConfig](ctx, docRef)
+	data, err := getDocument[mailer.MailgunConfig](ctx, c, DocumentPath(TokenCollectionKey, MailgunDocumentKey))
 	if err != nil {
 		return nil, err
 	}
@@ -85,9 +88,7 @@ func (c *Client) GetMailgunConfig(ctx context.Context) (*mailer.MailgunConfig, e
 }
 
 func (c *Client) GetSeasonIndex(ctx context.Context) (*model.FirestoreSeasonIndexDocument, error) {
-	docRef := c.fs.Collection(SeasonCollectionKey).Doc(SeasonCollectionIndexDocKey)
-
-	data, err := getDocument[model.FirestoreSeasonIndexDocument](ctx, docRef)
+	data, err := getDocument[model.FirestoreSeasonIndexDocument](ctx, c, DocumentPath(SeasonCollectionKey, SeasonCollectionIndexDocKey))
 	if err != nil {
 		return nil, err
 	}
@@ -95,39 +96,56 @@ func (c *Client) GetSeasonIndex(ctx context.Context) (*model.FirestoreSeasonInde
 	return data, nil
 }
 
-func (c *Client) UpdateMonoDocument(ctx context.Context, monoType model.MonoType, data model.FirestoreMonoDocument) error {
-	docRef := c.fs.Collection(MonoCollectionKey).Doc(string(monoType))
-	docData := map[string]interface{}{
-		"data":                          data,
-		FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
-	}
+func (c *Client) GetMonoDocument(ctx context.Context, monoType model.MonoType) (*model.FirestoreDocument[model.FirestoreMonoDocument], error) {
+	return getDocument[model.FirestoreDocument[model.FirestoreMonoDocument]](ctx, c, DocumentPath(MonoCollectionKey, string(monoType)))
+}
 
-	err := saveDocument(ctx, docRef, docData)
-	if err != nil {
-		return err
-	}
+func (c *Client) GetTrendingSubjects(ctx context.Context, subjectTypeID string) (*model.FirestoreDocument[[]model.FirestoreSubject], error) {
+	return getDocument[model.FirestoreDocument[[]model.FirestoreSubject]](ctx, c, DocumentPath(TrendingCollectionKey, subjectTypeID))
+}
 
-	return nil
+func (c *Client) GetSeasonalSubjects(ctx context.Context, id string) (*model.FirestoreDocument[[]model.FirestoreSeasonSubject], error) {
+	return getDocument[model.FirestoreDocument[[]model.FirestoreSeasonSubject]](ctx, c, DocumentPath(SeasonCollectionKey, id))
 }
 
-func (c *Client) UpdateSeasonIndex(ctx context.Context, items []model.FirestoreSeasonIndexItem) error {
-	docRef := c.fs.Collection(SeasonCollectionKey).Doc(SeasonCollectionIndexDocKey)
+func (c *Client) GetDiscoverySubjects(ctx context.Context, id model.SubjectTypeID) (*model.FirestoreDocument[[]model.FirestoreDiscoverySubject], error) {
+	return getDocument[model.FirestoreDocument[[]model.FirestoreDiscoverySubject]](ctx, c, DocumentPath(DiscoveryCollectionKey, string(id)))
+}
 
-	data := map[string]interface{}{
-		"data":                          items,
-		FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
+func (c *Client) GetRelatedSubjects(ctx context.Context, subjectID int) (*model.FirestoreDocument[[]model.FirestoreSubject], error) {
+	return getDocument[model.FirestoreDocument[[]model.FirestoreSubject]](ctx, c, DocumentPath(RelatedCollectionKey, strconv.Itoa(subjectID)))
+}
+
+func (c *Client) UpdateMonoDocument(ctx context.Context, monoType model.MonoType, data model.FirestoreMonoDocument) (Diff, error) {
+	diff, err := c.publish(ctx, monoDocument(monoType, data))
+	if err != nil {
+		return Diff{}, err
 	}
 
-	err := saveDocument(ctx, docRef, data)
+	return diff, nil
+}
+
+func (c *Client) UpdateSeasonIndex(ctx context.Context, items []model.FirestoreSeasonIndexItem) (Diff, error) {
+	diff, err := c.publish(ctx, seasonIndexDocument(items))
 	if err != nil {
-		return err
+		return Diff{}, err
 	}
 
-	return nil
+	return diff, nil
 }
 
+// UpdateBangumiToken stores a refreshed token. In dry-run mode it is logged instead, without its payload.
 func (c *Client) UpdateBangumiToken(ctx context.Context, accessToken string, refreshToken string) error {
-	docRef := c.fs.Collection(TokenCollectionKey).Doc(TokenCollectionBangumiDocKey)
+	if c.dryRun {
+		c.logger.WithField("path", DocumentPath(TokenCollectionKey, TokenCollectionBangumiDocKey)).Info("fs dry run: skipped write")
+		return nil
+	}
+
+	return c.saveBangumiToken(ctx, accessToken, refreshToken)
+}
+
+func (c *Client) saveBangumiToken(ctx context.Context, accessToken string, refreshToken string) error {
+	path := DocumentPath(TokenCollectionKey, TokenCollectionBangumiDocKey)
 
 	data := map[string]interface{}{
 		BangumiAccessTokenKey:           accessToken,
@@ -135,7 +153,7 @@ func (c *Client) UpdateBangumiToken(ctx context.Context, accessToken string, ref
 		FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
 	}
 
-	err := saveDocument(ctx, docRef, data)
+	err := saveDocument(ctx, c.store, path, data)
 	if err != nil {
 		return err
 	}
@@ -143,67 +161,50 @@ func (c *Client) UpdateBangumiToken(ctx context.Context, accessToken string, ref
 	return nil
 }
 
-func (c *Client) UpdateTrendingSubjects(ctx context.Context, subjectTypeID string, subjects []model.FirestoreSubject) error {
-	docRef := c.fs.Collection("trending").Doc(subjectTypeID)
-
-	data := map[string]interface{}{
-		"data":                          subjects,
-		FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
-	}
-
-	err := saveDocument(ctx, docRef, data)
+func (c *Client) UpdateTrendingSubjects(ctx context.Context, subjectTypeID string, subjects []model.FirestoreSubject) (Diff, error) {
+	diff, err := c.publish(ctx, trendingSubjectsDocument(subjectTypeID, subjects))
 	if err != nil {
-		return err
+		return Diff{}, err
 	}
 
-	return nil
+	return diff, nil
 }
 
-func (c *Client) UpdateSeasonalSubjects(ctx context.Context, id string, subjects []model.FirestoreSeasonSubject) error {
-	docRef := c.fs.Collection("season").Doc(id)
-
-	data := map[string]interface{}{
-		"data":                          subjects,
-		"total":                         len(subjects),
-		FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
-	}
-
-	err := saveDocument(ctx, docRef, data)
+func (c *Client) UpdateSeasonalSubjects(ctx context.Context, id string, subjects []model.FirestoreSeasonSubject) (Diff, error) {
+	diff, err := c.publish(ctx, seasonalSubjectsDocument(id, subjects))
 	if err != nil {
-		return err
+		return Diff{}, err
 	}
 
-	return nil
+	return diff, nil
 }
 
-func (c *Client) UpdateDiscoverySubjects(ctx context.Context, id model.SubjectTypeID, data []model.FirestoreDiscoverySubject) error {
-	docRef := c.fs.Collection(DiscoveryCollectionKey).Doc(string(id))
-
-	docData := map[string]interface{}{
-		"data":                          data,
-		FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
-	}
-
-	err := saveDocument(ctx, docRef, docData)
+func (c *Client) UpdateDiscoverySubjects(ctx context.Context, id model.SubjectTypeID, data []model.FirestoreDiscoverySubject) (Diff, error) {
+	diff, err := c.publish(ctx, discoverySubjectsDocument(id, data))
 	if err != nil {
-		return err
+		return Diff{}, err
 	}
 
-	return nil
+	return diff, nil
 }
 
-func getDocument[T any](ctx context.Context, docRef *firestore.DocumentRef) (*T, error) {
-	docSnap, err := docRef.Get(ctx)
+func (c *Client) UpdateRelatedSubjects(ctx context.Context, subjectID int, subjects []model.FirestoreSubject) (Diff, error) {
+	diff, err := c.publish(ctx, relatedSubjectsDocument(subjectID, subjects))
 	if err != nil {
-		return nil, err
+		return Diff{}, err
 	}
 
-	if !docSnap.Exists() {
-		return nil, ErrDocumentDoesNotExist
-	}
+	return diff, nil
+}
+
+// DocumentPath joins a collection and a document id into a document path, e.g. "season/202504".
+func DocumentPath(collection string, id string) string {
+	return collection + "/" + id
+}
 
+func getDocument[T any](ctx context.Context, c *Client, path string) (*T, error) {
 	var result T
-	err = docSnap.DataTo(&result)
+	err := readTo(ctx, c.store, path, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -211,8 +212,8 @@ func getDocument[T any](ctx context.Context, docRef *firestore.DocumentRef) (*T,
 	return &result, nil
 }
 
-func saveDocument(ctx context.Context, docRef *firestore.DocumentRef, data map[string]interface{}) error {
-	_, err := docRef.Set(ctx, data, firestore.MergeAll)
+func saveDocument(ctx context.Context, s store, path string, data map[string]interface{}) error {
+	err := s.set(ctx, path, data, true)
 
 	if err != nil {
 		return err
@@ -0,0 +1,416 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The codec converts Go values to and from the generic form the firestore client returns from
+// DocumentSnapshot.Data: nil, bool, int64, float64, string, []byte, time.Time, []interface{} and
+// map[string]interface{}. It follows the same `firestore:"name,omitempty"` struct tags so that values
+// kept by the memory store read back as they would from Firestore. The firestore backend does not
+// decode with it, it uses the client's own DataTo.
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	sentinelType = reflect.TypeOf(firestore.ServerTimestamp)
+)
+
+// encode converts v into its generic form. The firestore.Delete and firestore.ServerTimestamp
+// sentinels are kept as is and resolved by the caller.
+func encode(v interface{}) (interface{}, error) {
+	return encodeValue(reflect.ValueOf(v))
+}
+
+func encodeValue(v reflect.Value) (interface{}, error) {
+	if v.IsValid() && v.Type() == sentinelType {
+		return v.Interface(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append([]byte(nil), v.Bytes()...), nil
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			e, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("fs: cannot encode map with %s keys", v.Type().Key())
+		}
+		if v.IsNil() {
+			return nil, nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			e, err := encodeValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			out[iter.Key().String()] = e
+		}
+		return out, nil
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).Truncate(time.Microsecond), nil
+		}
+		out := map[string]interface{}{}
+		if err := encodeStruct(v, out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("fs: cannot encode value of type %s", v.Type())
+}
+
+func encodeStruct(v reflect.Value, out map[string]interface{}) error {
+	for _, f := range structFields(v.Type()) {
+		fv := v.FieldByIndex(f.index)
+
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		e, err := encodeValue(fv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		out[f.name] = e
+	}
+
+	return nil
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the encoded fields of a struct type, flattening embedded structs like firestore does.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("firestore")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for _, embedded := range structFields(f.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, structField{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(opts, "omitempty"),
+		})
+	}
+
+	return fields
+}
+
+// decode stores the generic value src into the value dst points to.
+func decode(src interface{}, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("fs: decode requires a non-nil pointer, got %T", dst)
+	}
+
+	return decodeValue(src, v.Elem())
+}
+
+func decodeValue(src interface{}, dst reflect.Value) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return decodeValue(src, dst.Elem())
+	case reflect.Interface:
+		if dst.NumMethod() == 0 {
+			dst.Set(reflect.ValueOf(src))
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := src.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := src.(type) {
+		case int64:
+			if dst.OverflowInt(n) {
+				return fmt.Errorf("fs: %d overflows %s", n, dst.Type())
+			}
+			dst.SetInt(n)
+			return nil
+		case float64:
+			i := int64(n)
+			if float64(i) != n || dst.OverflowInt(i) {
+				return fmt.Errorf("fs: cannot decode %v into %s without loss", n, dst.Type())
+			}
+			dst.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch n := src.(type) {
+		case int64:
+			if n < 0 || dst.OverflowUint(uint64(n)) {
+				return fmt.Errorf("fs: %d overflows %s", n, dst.Type())
+			}
+			dst.SetUint(uint64(n))
+			return nil
+		case float64:
+			u := uint64(n)
+			if n < 0 || float64(u) != n || dst.OverflowUint(u) {
+				return fmt.Errorf("fs: cannot decode %v into %s without loss", n, dst.Type())
+			}
+			dst.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n := src.(type) {
+		case int64:
+			dst.SetFloat(float64(n))
+			return nil
+		case float64:
+			dst.SetFloat(n)
+			return nil
+		}
+	case reflect.String:
+		if s, ok := src.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+	case reflect.Slice:
+		if b, ok := src.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		if a, ok := src.([]interface{}); ok {
+			s := reflect.MakeSlice(dst.Type(), len(a), len(a))
+			for i, e := range a {
+				if err := decodeValue(e, s.Index(i)); err != nil {
+					return err
+				}
+			}
+			dst.Set(s)
+			return nil
+		}
+	case reflect.Array:
+		if a, ok := src.([]interface{}); ok {
+			for i := 0; i < dst.Len(); i++ {
+				var e interface{}
+				if i < len(a) {
+					e = a[i]
+				}
+				if err := decodeValue(e, dst.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := src.(map[string]interface{}); ok && dst.Type().Key().Kind() == reflect.String {
+			out := reflect.MakeMapWithSize(dst.Type(), len(m))
+			for k, e := range m {
+				ev := reflect.New(dst.Type().Elem()).Elem()
+				if err := decodeValue(e, ev); err != nil {
+					return err
+				}
+				out.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), ev)
+			}
+			dst.Set(out)
+			return nil
+		}
+	case reflect.Struct:
+		if dst.Type() == timeType {
+			if t, ok := src.(time.Time); ok {
+				dst.Set(reflect.ValueOf(t))
+				return nil
+			}
+			break
+		}
+		if m, ok := src.(map[string]interface{}); ok {
+			return decodeStruct(m, dst)
+		}
+	}
+
+	return fmt.Errorf("fs: cannot decode %T into %s", src, dst.Type())
+}
+
+func decodeStruct(m map[string]interface{}, dst reflect.Value) error {
+	fields := structFields(dst.Type())
+
+	for key, e := range m {
+		f, ok := matchField(fields, key)
+		if !ok {
+			continue
+		}
+
+		if err := decodeValue(e, fieldByIndexAlloc(dst, f.index)); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// matchField finds the field for a key, exactly first and then case-insensitively.
+func matchField(fields []structField, key string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+
+	return structField{}, false
+}
+
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// compareValues orders two generic values of the same kind, reporting false if they are not comparable.
+func compareValues(a interface{}, b interface{}) (int, bool) {
+	if an, ok := toFloat(a); ok {
+		if bn, ok := toFloat(b); ok {
+			switch {
+			case an < bn:
+				return -1, true
+			case an > bn:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv), true
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv), true
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0, true
+			case !av:
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// clone deep copies a generic value so that callers cannot modify stored documents.
+func clone(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			out[k] = clone(e)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i, e := range x {
+			out[i] = clone(e)
+		}
+		return out
+	case []byte:
+		return append([]byte(nil), x...)
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/bangumilite/bangumilite-component/mailer"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("fs codec unit tests", func() {
+	Describe("encode", func() {
+		It("uses firestore tags and omits empty fields", func() {
+			got, err := encode(model.FirestoreSubject{ID: 1, Name: "name", Score: 7.5})
+
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(map[string]interface{}{
+				"id":         int64(1),
+				"name":       "name",
+				"name_cn":    "",
+				"info":       "",
+				"score":      7.5,
+				"collection": int64(0),
+			}))
+		})
+
+		It("keeps sentinels and encodes nested values", func() {
+			got, err := encode(map[string]interface{}{
+				"data":                          []model.FirestoreSeasonIndexItem{{ID: "202504", Image: "a"}},
+				FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
+			})
+
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(map[string]interface{}{
+				"data":                          []interface{}{map[string]interface{}{"id": "202504", "image": "a"}},
+				FirebaseLastUpdatedTimestampKey: firestore.ServerTimestamp,
+			}))
+		})
+
+		It("rejects maps without string keys", func() {
+			_, err := encode(map[int]string{1: "a"})
+
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Describe("decode", func() {
+		It("round trips models", func() {
+			image := "image"
+			relation := "主角"
+			want := model.FirestoreMonoDocument{
+				Trending: []model.FirestoreMono{{
+					ID:    1,
+					Name:  "name",
+					Image: &image,
+					RelatedSubjects: &[]model.FirestoreMonoRelatedWork{
+						{ID: 2, Name: "work", Relation: &relation},
+					},
+				}},
+			}
+
+			encoded, err := encode(want)
+			Expect(err).To(BeNil())
+
+			var got model.FirestoreMonoDocument
+			Expect(decode(encoded, &got)).To(Succeed())
+			Expect(got).To(Equal(want))
+		})
+
+		It("converts numbers and ignores unknown fields", func() {
+			var got mailer.MailgunConfig
+			err := decode(map[string]interface{}{
+				"domain":                  "example.com",
+				"notification_recipients": []interface{}{"a@example.com"},
+				"unknown":                 int64(1),
+			}, &got)
+
+			Expect(err).To(BeNil())
+			Expect(got.Domain).To(Equal("example.com"))
+			Expect(got.NotificationRecipients).To(Equal([]string{"a@example.com"}))
+
+			var score struct {
+				Score float64 `firestore:"score"`
+				Rank  int     `firestore:"rank"`
+			}
+			Expect(decode(map[string]interface{}{"score": int64(8), "rank": 3.0}, &score)).To(Succeed())
+			Expect(score.Score).To(Equal(8.0))
+			Expect(score.Rank).To(Equal(3))
+		})
+
+		It("returns error on lossy number conversions", func() {
+			var got struct {
+				Rank  int  `firestore:"rank"`
+				Small int8 `firestore:"small"`
+				Count uint `firestore:"count"`
+			}
+
+			Expect(decode(map[string]interface{}{"rank": 3.5}, &got)).ToNot(Succeed())
+			Expect(decode(map[string]interface{}{"rank": 1e20}, &got)).ToNot(Succeed())
+			Expect(decode(map[string]interface{}{"small": int64(300)}, &got)).ToNot(Succeed())
+			Expect(decode(map[string]interface{}{"count": int64(-1)}, &got)).ToNot(Succeed())
+		})
+
+		It("decodes times", func() {
+			t := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+			var got model.FirestoreSubjectSnapshot
+
+			Expect(decode(map[string]interface{}{"timestamp": t}, &got)).To(Succeed())
+			Expect(got.Timestamp).To(Equal(t))
+		})
+
+		It("returns error on mismatched types", func() {
+			var got model.FirestoreSubject
+
+			Expect(decode(map[string]interface{}{"id": "1"}, &got)).ToNot(Succeed())
+		})
+	})
+})
@@ -0,0 +1,344 @@
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FirebaseContentHashKey holds the hash of the published content, a publish with the same content
+// is skipped.
+const FirebaseContentHashKey = "contentHash"
+
+// metadataKeys are the fields that change with every write and are not part of the content.
+var metadataKeys = map[string]bool{
+	FirebaseLastUpdatedTimestampKey: true,
+	FirebaseLastUpdatedByKey:        true,
+	FirebaseContentHashKey:          true,
+	FirebaseShardsKey:               true,
+	FirebaseArchivedByKey:           true,
+	FirebaseArchivedDateKey:         true,
+}
+
+// Diff describes what a publish changed in a document.
+type Diff struct {
+	Path string
+
+	// Skipped is set when the content hash was unchanged and nothing was written.
+	Skipped bool
+
+	// Created is set when the document did not exist.
+	Created bool
+
+	// Added, Removed, Changed and Moved list the items of the "data" array by id, when every item has
+	// a unique one. Moved are the kept items whose position relative to the other kept items changed,
+	// as few as explain the new order.
+	Added   []int
+	Removed []int
+	Changed []ItemDiff
+	Moved   []int
+
+	// Fields lists every other changed field as a dotted path, e.g. "total" or "data.trending".
+	Fields []string
+}
+
+// ItemDiff lists the changed fields of an item of the "data" array.
+type ItemDiff struct {
+	ID     int
+	Fields []string
+}
+
+// Empty reports whether the publish changed nothing.
+func (d Diff) Empty() bool {
+	return !d.Created && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.Moved) == 0 &&
+		len(d.Fields) == 0
+}
+
+// String summarises the diff for logs and notifications, e.g. "season/202504: +2 -1 ~3 moved 1 fields: total".
+func (d Diff) String() string {
+	switch {
+	case d.Skipped:
+		return d.Path + ": unchanged"
+	case d.Created:
+		return fmt.Sprintf("%s: created with %d items", d.Path, len(d.Added))
+	}
+
+	var b strings.Builder
+	b.WriteString(d.Path + ":")
+	if len(d.Added)+len(d.Removed)+len(d.Changed) > 0 {
+		fmt.Fprintf(&b, " +%d -%d ~%d", len(d.Added), len(d.Removed), len(d.Changed))
+	}
+	if len(d.Moved) > 0 {
+		fmt.Fprintf(&b, " moved %d", len(d.Moved))
+	}
+	if len(d.Fields) > 0 {
+		b.WriteString(" fields: " + strings.Join(d.Fields, ", "))
+	}
+	if d.Empty() {
+		b.WriteString(" no content changes")
+	}
+
+	return b.String()
+}
+
+// content returns the encoded document without metadata fields.
+func content(data map[string]interface{}) (map[string]interface{}, error) {
+	encoded, err := encode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := encoded.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("fs: document data must be a struct or map, got %T", data)
+	}
+
+	out := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		if !metadataKeys[k] {
+			out[k] = v
+		}
+	}
+
+	return out, nil
+}
+
+// contentHash hashes the content of an encoded document. Map keys are sorted by encoding/json, so the
+// hash is stable.
+func contentHash(content map[string]interface{}) (string, error) {
+	b, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// diff compares the content of the current document, nil if it does not exist, with the new content.
+func diff(path string, current map[string]interface{}, next map[string]interface{}) Diff {
+	d := Diff{Path: path}
+
+	if current == nil {
+		d.Created = true
+		d.Added, _ = itemIDs(next[firebaseDataKey])
+		return d
+	}
+
+	for k := range current {
+		if metadataKeys[k] {
+			delete(current, k)
+		}
+	}
+
+	oldIDs, oldItems, oldOK := itemsByID(current[firebaseDataKey])
+	newIDs, newItems, newOK := itemsByID(next[firebaseDataKey])
+	if oldOK && newOK {
+		d.Added, d.Removed, d.Changed = diffItems(oldItems, newItems)
+		d.Moved = movedItems(oldIDs, newIDs)
+		delete(current, firebaseDataKey)
+		next = withoutKey(next, firebaseDataKey)
+	}
+
+	d.Fields = diffFields("", current, next)
+
+	return d
+}
+
+func diffItems(old map[int]map[string]interface{}, next map[int]map[string]interface{}) ([]int, []int, []ItemDiff) {
+	var added, removed []int
+	var changed []ItemDiff
+
+	for id, item := range next {
+		prev, ok := old[id]
+		if !ok {
+			added = append(added, id)
+			continue
+		}
+
+		if fields := diffFields("", prev, item); len(fields) > 0 {
+			changed = append(changed, ItemDiff{ID: id, Fields: fields})
+		}
+	}
+
+	for id := range old {
+		if _, ok := next[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+
+	sort.Ints(added)
+	sort.Ints(removed)
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].ID < changed[j].ID
+	})
+
+	return added, removed, changed
+}
+
+// movedItems returns the ids kept from old to next that are not part of the longest run of kept ids
+// whose order is unchanged, sorted.
+func movedItems(old []int, next []int) []int {
+	position := make(map[int]int, len(next))
+	for i, id := range next {
+		position[id] = i
+	}
+
+	// kept are the new positions of the kept items in their old order.
+	var kept []int
+	for _, id := range old {
+		if i, ok := position[id]; ok {
+			kept = append(kept, i)
+		}
+	}
+
+	// tails[k] indexes kept at the smallest tail of an increasing run of length k+1, prev links the
+	// runs back so that the longest one can be recovered.
+	var tails []int
+	prev := make([]int, len(kept))
+	for i, p := range kept {
+		k := sort.Search(len(tails), func(k int) bool { return kept[tails[k]] >= p })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	inOrder := make(map[int]bool, len(tails))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			inOrder[kept[i]] = true
+		}
+	}
+
+	var moved []int
+	for _, p := range kept {
+		if !inOrder[p] {
+			moved = append(moved, next[p])
+		}
+	}
+	sort.Ints(moved)
+
+	return moved
+}
+
+// diffFields returns the dotted paths of the fields that differ, descending into maps and into arrays
+// of the same length.
+func diffFields(prefix string, old interface{}, next interface{}) []string {
+	if reflect.DeepEqual(old, next) {
+		return nil
+	}
+
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+
+	switch o := old.(type) {
+	case time.Time:
+		// Read timestamps may differ in location only.
+		if n, ok := next.(time.Time); ok && o.Equal(n) {
+			return nil
+		}
+	case map[string]interface{}:
+		n, ok := next.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make(map[string]bool, len(o)+len(n))
+		for k := range o {
+			keys[k] = true
+		}
+		for k := range n {
+			keys[k] = true
+		}
+
+		var fields []string
+		for k := range keys {
+			fields = append(fields, diffFields(join(k), o[k], n[k])...)
+		}
+		sort.Strings(fields)
+		return fields
+	case []interface{}:
+		n, ok := next.([]interface{})
+		if !ok || len(o) != len(n) {
+			break
+		}
+
+		var fields []string
+		for i := range o {
+			fields = append(fields, diffFields(join(strconv.Itoa(i)), o[i], n[i])...)
+		}
+		return fields
+	}
+
+	return []string{prefix}
+}
+
+// itemsByID indexes an array of items by their "id" field and lists the ids in order, reporting false
+// unless every item has a unique one.
+func itemsByID(v interface{}) ([]int, map[int]map[string]interface{}, bool) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+
+	ids := make([]int, 0, len(items))
+	byID := make(map[int]map[string]interface{}, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil, false
+		}
+
+		id, ok := m["id"].(int64)
+		if !ok {
+			return nil, nil, false
+		}
+
+		if _, ok := byID[int(id)]; ok {
+			return nil, nil, false
+		}
+
+		ids = append(ids, int(id))
+		byID[int(id)] = m
+	}
+
+	return ids, byID, true
+}
+
+func itemIDs(v interface{}) ([]int, bool) {
+	ids, _, ok := itemsByID(v)
+	if !ok {
+		return nil, false
+	}
+
+	sort.Ints(ids)
+
+	return ids, true
+}
+
+func withoutKey(m map[string]interface{}, key string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k != key {
+			out[k] = v
+		}
+	}
+	return out
+}
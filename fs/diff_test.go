@@ -0,0 +1,176 @@
+package fs
+
+import (
+	"context"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("fs diff unit tests", func() {
+	var (
+		ctx  context.Context
+		m    *Memory
+		now  time.Time
+		path string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+		path = DocumentPath(SeasonCollectionKey, "202504")
+
+		m = NewMemory()
+		m.SetClock(func() time.Time { return now })
+	})
+
+	publish := func(subjects []model.FirestoreSeasonSubject) Diff {
+		diffs, err := m.Publish(ctx, NewBatch().UpdateSeasonalSubjects("202504", subjects))
+		Expect(err).To(BeNil())
+		Expect(diffs).To(HaveLen(1))
+		return diffs[0]
+	}
+
+	It("stores the content hash", func() {
+		publish([]model.FirestoreSeasonSubject{{ID: 1}})
+
+		doc, ok := m.Document(path)
+		Expect(ok).To(BeTrue())
+		Expect(doc[FirebaseContentHashKey]).To(HaveLen(64))
+	})
+
+	It("skips documents whose content is unchanged", func() {
+		subjects := []model.FirestoreSeasonSubject{{ID: 1, Name: "a"}}
+		publish(subjects)
+
+		now = now.Add(time.Hour)
+		d := publish(subjects)
+
+		Expect(d.Skipped).To(BeTrue())
+		Expect(d.String()).To(Equal("season/202504: unchanged"))
+		Expect(m.Writes()).To(HaveLen(1))
+
+		got, err := m.GetSeasonalSubjects(ctx, "202504")
+		Expect(err).To(BeNil())
+		Expect(got.LastUpdatedDate).To(Equal(now.Add(-time.Hour)))
+
+		versions, err := m.ListVersions(ctx, path)
+		Expect(err).To(BeNil())
+		Expect(versions).To(BeEmpty())
+	})
+
+	It("returns diffs from the Update methods and skips unchanged documents", func() {
+		created, err := m.UpdateSeasonIndex(ctx, []model.FirestoreSeasonIndexItem{{ID: "202504"}})
+		Expect(err).To(BeNil())
+		Expect(created.Created).To(BeTrue())
+		Expect(created.Path).To(Equal(DocumentPath(SeasonCollectionKey, SeasonCollectionIndexDocKey)))
+
+		skipped, err := m.UpdateSeasonIndex(ctx, []model.FirestoreSeasonIndexItem{{ID: "202504"}})
+		Expect(err).To(BeNil())
+		Expect(skipped.Skipped).To(BeTrue())
+
+		Expect(m.Writes()).To(HaveLen(1))
+	})
+
+	It("reports created documents", func() {
+		d := publish([]model.FirestoreSeasonSubject{{ID: 2}, {ID: 1}})
+
+		Expect(d.Created).To(BeTrue())
+		Expect(d.Added).To(Equal([]int{1, 2}))
+		Expect(d.String()).To(Equal("season/202504: created with 2 items"))
+	})
+
+	It("reports added, removed and changed subjects", func() {
+		publish([]model.FirestoreSeasonSubject{{ID: 1, Name: "a"}, {ID: 2}, {ID: 3, Staff: []string{"x"}}})
+
+		d := publish([]model.FirestoreSeasonSubject{{ID: 1, Name: "b"}, {ID: 3, Staff: []string{"y"}}, {ID: 4}, {ID: 5}})
+
+		Expect(d.Skipped).To(BeFalse())
+		Expect(d.Added).To(Equal([]int{4, 5}))
+		Expect(d.Removed).To(Equal([]int{2}))
+		Expect(d.Changed).To(Equal([]ItemDiff{
+			{ID: 1, Fields: []string{"name"}},
+			{ID: 3, Fields: []string{"staff.0"}},
+		}))
+		Expect(d.Fields).To(Equal([]string{"total"}))
+		Expect(d.String()).To(Equal("season/202504: +2 -1 ~2 fields: total"))
+	})
+
+	It("reports reordered subjects", func() {
+		publish([]model.FirestoreSeasonSubject{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}})
+
+		d := publish([]model.FirestoreSeasonSubject{{ID: 2}, {ID: 3}, {ID: 4}, {ID: 1}})
+
+		Expect(d.Skipped).To(BeFalse())
+		Expect(d.Added).To(BeEmpty())
+		Expect(d.Removed).To(BeEmpty())
+		Expect(d.Changed).To(BeEmpty())
+		Expect(d.Moved).To(Equal([]int{1}))
+		Expect(d.Empty()).To(BeFalse())
+		Expect(d.String()).To(Equal("season/202504: moved 1"))
+
+		Expect(movedItems([]int{1, 2, 3, 4, 5}, []int{5, 1, 2, 3, 4})).To(Equal([]int{5}))
+		Expect(movedItems([]int{1, 2, 3, 4}, []int{4, 3, 2, 1})).To(HaveLen(3))
+		Expect(movedItems([]int{1, 2, 3}, []int{1, 2, 3})).To(BeEmpty())
+	})
+
+	It("does not report items as moved by insertions and removals", func() {
+		publish([]model.FirestoreSeasonSubject{{ID: 1}, {ID: 2}, {ID: 3}})
+
+		d := publish([]model.FirestoreSeasonSubject{{ID: 4}, {ID: 1}, {ID: 3}})
+
+		Expect(d.Moved).To(BeEmpty())
+		Expect(d.String()).To(Equal("season/202504: +1 -1 ~0"))
+	})
+
+	It("diffs items with duplicate ids by position", func() {
+		d := diff(path, map[string]interface{}{
+			"data": []interface{}{
+				map[string]interface{}{"id": int64(1), "name": "a"},
+				map[string]interface{}{"id": int64(1), "name": "b"},
+			},
+		}, map[string]interface{}{
+			"data": []interface{}{
+				map[string]interface{}{"id": int64(1), "name": "b"},
+				map[string]interface{}{"id": int64(1), "name": "a"},
+			},
+		})
+
+		Expect(d.Changed).To(BeEmpty())
+		Expect(d.Fields).To(Equal([]string{"data.0.name", "data.1.name"}))
+		Expect(d.Empty()).To(BeFalse())
+	})
+
+	It("diffs sharded documents", func() {
+		m = NewMemory(WithShardThreshold(500))
+		m.SetClock(func() time.Time { return now })
+
+		subjects := make([]model.FirestoreSeasonSubject, 10)
+		for i := range subjects {
+			subjects[i] = model.FirestoreSeasonSubject{ID: i + 1, Summary: "summary of the subject"}
+		}
+		publish(subjects)
+		doc, _ := m.Document(path)
+		Expect(doc[FirebaseShardsKey]).ToNot(BeNil())
+
+		subjects[9].Summary = "changed"
+		d := publish(subjects)
+
+		Expect(d.Changed).To(Equal([]ItemDiff{{ID: 10, Fields: []string{"summary"}}}))
+		Expect(d.Fields).To(BeEmpty())
+	})
+
+	It("ignores metadata fields", func() {
+		d := diff(path, map[string]interface{}{
+			FirebaseLastUpdatedTimestampKey: now,
+			FirebaseContentHashKey:          "old",
+			"total":                         int64(1),
+		}, map[string]interface{}{
+			"total": int64(1),
+		})
+
+		Expect(d.Empty()).To(BeTrue())
+		Expect(d.String()).To(Equal("season/202504: no content changes"))
+	})
+})
@@ -0,0 +1,62 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/bangumilite/bangumilite-component/model"
+	"strconv"
+)
+
+// pendingDocument is a published document about to be written, built by the Update* methods of
+// Client and Batch alike.
+type pendingDocument struct {
+	path  string
+	data  map[string]interface{}
+	merge bool
+}
+
+func newPendingDocument(collection string, id string, data map[string]interface{}) pendingDocument {
+	data[FirebaseLastUpdatedTimestampKey] = firestore.ServerTimestamp
+
+	return pendingDocument{
+		path:  DocumentPath(collection, id),
+		data:  data,
+		merge: true,
+	}
+}
+
+func monoDocument(monoType model.MonoType, data model.FirestoreMonoDocument) pendingDocument {
+	return newPendingDocument(MonoCollectionKey, string(monoType), map[string]interface{}{
+		"data": data,
+	})
+}
+
+func seasonIndexDocument(items []model.FirestoreSeasonIndexItem) pendingDocument {
+	return newPendingDocument(SeasonCollectionKey, SeasonCollectionIndexDocKey, map[string]interface{}{
+		"data": items,
+	})
+}
+
+func trendingSubjectsDocument(subjectTypeID string, subjects []model.FirestoreSubject) pendingDocument {
+	return newPendingDocument(TrendingCollectionKey, subjectTypeID, map[string]interface{}{
+		"data": subjects,
+	})
+}
+
+func seasonalSubjectsDocument(id string, subjects []model.FirestoreSeasonSubject) pendingDocument {
+	return newPendingDocument(SeasonCollectionKey, id, map[string]interface{}{
+		"data":  subjects,
+		"total": len(subjects),
+	})
+}
+
+func discoverySubjectsDocument(id model.SubjectTypeID, data []model.FirestoreDiscoverySubject) pendingDocument {
+	return newPendingDocument(DiscoveryCollectionKey, string(id), map[string]interface{}{
+		"data": data,
+	})
+}
+
+func relatedSubjectsDocument(subjectID int, subjects []model.FirestoreSubject) pendingDocument {
+	return newPendingDocument(RelatedCollectionKey, strconv.Itoa(subjectID), map[string]interface{}{
+		"data": subjects,
+	})
+}
@@ -0,0 +1,54 @@
+package fs
+
+import (
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// report logs the diff and the estimated size of a prepared plan instead of applying it, and writes
+// its payload to the dry-run directory if one is set.
+func (c *Client) report(plan *publishPlan) error {
+	size, err := EstimateSize(plan.doc.path, plan.doc.data)
+	if err != nil {
+		return err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"path":     plan.doc.path,
+		"diff":     plan.diff.String(),
+		"size":     size,
+		"headroom": MaxDocumentSize - size,
+		"sharded":  c.shardThreshold > 0 && size > c.shardThreshold,
+	}).Info("fs dry run: skipped write")
+
+	if c.dryRunDir == "" {
+		return nil
+	}
+
+	return c.writePayload(plan.doc)
+}
+
+// writePayload writes the content of doc as an indented JSON file named after its path, e.g.
+// "season_202504.json".
+func (c *Client) writePayload(doc pendingDocument) error {
+	data, err := content(doc.data)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dryRunDir, 0o755); err != nil {
+		return err
+	}
+
+	name := strings.ReplaceAll(doc.path, "/", "_") + ".json"
+
+	return os.WriteFile(filepath.Join(c.dryRunDir, name), b, 0o644)
+}
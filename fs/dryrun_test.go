@@ -0,0 +1,137 @@
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var errRetry = errors.New("retry")
+
+// retryStore runs every transaction twice, failing the first attempt like a contended transaction.
+type retryStore struct {
+	store
+}
+
+func (s *retryStore) runTransaction(ctx context.Context, fn func(ctx context.Context, tx ops) error) error {
+	err := s.store.runTransaction(ctx, func(ctx context.Context, tx ops) error {
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+		return errRetry
+	})
+	if !errors.Is(err, errRetry) {
+		return err
+	}
+
+	return s.store.runTransaction(ctx, fn)
+}
+
+var _ = Describe("fs dry run unit tests", func() {
+	var (
+		ctx    context.Context
+		m      *Memory
+		logger *logrus.Logger
+		hook   *test.Hook
+		dir    string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		dir, err = os.MkdirTemp("", "fs-dry-run")
+		Expect(err).To(BeNil())
+
+		logger, hook = test.NewNullLogger()
+		m = NewMemory(WithDryRun(true), WithDryRunDir(dir), WithLogger(logger))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("logs the diff and size instead of writing", func() {
+		Expect(m.Seed("season/202504", map[string]interface{}{
+			"data":            []model.FirestoreSeasonSubject{{ID: 1}},
+			"total":           1,
+			"lastUpdatedDate": time.Now(),
+		})).To(Succeed())
+
+		subjects := []model.FirestoreSeasonSubject{{ID: 1}, {ID: 2}}
+		Expect(m.UpdateSeasonalSubjects(ctx, "202504", subjects)).Error().To(Succeed())
+
+		Expect(m.Writes()).To(BeEmpty())
+		got, err := m.GetSeasonalSubjects(ctx, "202504")
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(HaveLen(1))
+
+		entry := hook.LastEntry()
+		Expect(entry).ToNot(BeNil())
+		Expect(entry.Data["path"]).To(Equal("season/202504"))
+		Expect(entry.Data["diff"]).To(Equal("season/202504: +1 -0 ~0 fields: total"))
+		Expect(entry.Data["size"]).To(BeNumerically(">", 0))
+	})
+
+	It("writes payloads as JSON files", func() {
+		subjects := []model.FirestoreSubject{{ID: 1, Name: "name"}}
+		Expect(m.UpdateTrendingSubjects(ctx, "2", subjects)).Error().To(Succeed())
+
+		b, err := os.ReadFile(filepath.Join(dir, "trending_2.json"))
+		Expect(err).To(BeNil())
+
+		var payload map[string]interface{}
+		Expect(json.Unmarshal(b, &payload)).To(Succeed())
+		Expect(payload).To(HaveKey("data"))
+		Expect(payload).ToNot(HaveKey(FirebaseLastUpdatedTimestampKey))
+	})
+
+	It("reports batches without writing", func() {
+		batch := NewBatch().
+			UpdateSeasonIndex([]model.FirestoreSeasonIndexItem{{ID: "202504"}}).
+			UpdateRelatedSubjects(1, nil)
+
+		diffs, err := m.Publish(ctx, batch)
+
+		Expect(err).To(BeNil())
+		Expect(diffs).To(HaveLen(2))
+		Expect(diffs[0].Created).To(BeTrue())
+		Expect(m.Writes()).To(BeEmpty())
+		Expect(hook.AllEntries()).To(HaveLen(2))
+	})
+
+	It("reports batches once when the transaction is retried", func() {
+		c := newMemoryConfig(WithDryRun(true), WithLogger(logger)).newClient(&retryStore{store: m.mem})
+
+		diffs, err := c.Publish(ctx, NewBatch().UpdateSeasonIndex([]model.FirestoreSeasonIndexItem{{ID: "202504"}}))
+
+		Expect(err).To(BeNil())
+		Expect(diffs).To(HaveLen(1))
+		Expect(hook.AllEntries()).To(HaveLen(1))
+	})
+
+	It("does not write the token or its payload", func() {
+		Expect(m.UpdateBangumiToken(ctx, "access", "refresh")).To(Succeed())
+
+		Expect(m.Writes()).To(BeEmpty())
+		Expect(hook.LastEntry().Data["path"]).To(Equal("token/bangumi"))
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).To(BeNil())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("does not save subject snapshots", func() {
+		Expect(m.SaveSubjectSnapshots(ctx, []model.FirestoreSubjectSnapshot{{SubjectID: 1}})).To(Succeed())
+
+		Expect(m.Writes()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,81 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/history"
+	"github.com/bangumilite/bangumilite-component/model"
+	"time"
+)
+
+const (
+	SubjectHistoryCollectionKey = "subject_history"
+	SubjectHistoryTimestampKey  = "timestamp"
+	SubjectHistorySubjectIDKey  = "subject_id"
+)
+
+var _ history.Store = (*Client)(nil)
+
+// SaveSubjectSnapshots stores the snapshots, one document per subject and timestamp.
+func (c *Client) SaveSubjectSnapshots(ctx context.Context, snapshots []model.FirestoreSubjectSnapshot) error {
+	docs := make(map[string]interface{}, len(snapshots))
+	for _, snapshot := range snapshots {
+		id := fmt.Sprintf("%d_%d", snapshot.SubjectID, snapshot.Timestamp.Unix())
+		docs[DocumentPath(SubjectHistoryCollectionKey, id)] = snapshot
+	}
+
+	if c.dryRun {
+		c.logger.WithField("snapshots", len(docs)).Info("fs dry run: skipped saving subject snapshots")
+		return nil
+	}
+
+	return c.store.setAll(ctx, docs)
+}
+
+// GetSubjectSnapshots returns every snapshot taken at or after since.
+func (c *Client) GetSubjectSnapshots(ctx context.Context, since time.Time) ([]model.FirestoreSubjectSnapshot, error) {
+	docs, err := c.store.query(ctx, SubjectHistoryCollectionKey, nil, filter{
+		field: SubjectHistoryTimestampKey,
+		op:    ">=",
+		value: since,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSnapshots(docs)
+}
+
+// GetSubjectSnapshotsByID returns the snapshots of one subject taken at or after since. The query needs
+// a composite index on subject_id and timestamp.
+func (c *Client) GetSubjectSnapshotsByID(ctx context.Context, subjectID int, since time.Time) ([]model.FirestoreSubjectSnapshot, error) {
+	docs, err := c.store.query(ctx, SubjectHistoryCollectionKey, nil, filter{
+		field: SubjectHistorySubjectIDKey,
+		op:    "==",
+		value: subjectID,
+	}, filter{
+		field: SubjectHistoryTimestampKey,
+		op:    ">=",
+		value: since,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSnapshots(docs)
+}
+
+func decodeSnapshots(docs []document) ([]model.FirestoreSubjectSnapshot, error) {
+	snapshots := make([]model.FirestoreSubjectSnapshot, 0, len(docs))
+	for _, doc := range docs {
+		var snapshot model.FirestoreSubjectSnapshot
+		if err := doc.dataTo(&snapshot); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
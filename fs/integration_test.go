@@ -0,0 +1,45 @@
+//go:build integration
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"os"
+)
+
+// The integration specs run the Repository specs against a local Firestore emulator:
+//
+//	gcloud emulators firestore start --host-port=localhost:8080
+//	FIRESTORE_EMULATOR_HOST=localhost:8080 go test -tags integration ./fs/...
+
+const integrationProjectID = "demo-bangumilite"
+
+var _ = Describe("fs emulator integration tests", func() {
+	host := os.Getenv(EmulatorHostEnv)
+
+	BeforeEach(func() {
+		if host == "" {
+			Skip(EmulatorHostEnv + " is not set")
+		}
+
+		// The emulator exposes an endpoint to drop every document of a project.
+		url := fmt.Sprintf("http://%s/emulator/v1/projects/%s/databases/(default)/documents", host, integrationProjectID)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		Expect(err).To(BeNil())
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	describeRepository(func() Repository {
+		c, err := New(context.Background(), WithEmulator(host), WithProjectID(integrationProjectID))
+		Expect(err).To(BeNil())
+		return c
+	})
+})
@@ -0,0 +1,254 @@
+package fs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/bangumi"
+	"github.com/bangumilite/bangumilite-component/model"
+	"os"
+	"time"
+)
+
+const (
+	LeaseCollectionKey = "lease"
+
+	// DefaultLeaseTTL is how long a lease is held without renewal.
+	DefaultLeaseTTL = time.Minute
+
+	// DefaultLeaseClockSkew is the largest difference between the clocks of two jobs that leases allow for.
+	DefaultLeaseClockSkew = 5 * time.Second
+
+	// TokenLeaseName and SeasonLeaseName guard refreshing the Bangumi token and publishing seasons.
+	TokenLeaseName  = "bangumi_token"
+	SeasonLeaseName = "season"
+
+	leaseExpireDateKey = "expireDate"
+)
+
+var (
+	ErrLeaseHeld = errors.New("lease is held by another owner")
+	ErrLeaseLost = errors.New("lease lost")
+)
+
+// Lease is a named lock stored in the lease collection. It is held by its owner until it is released
+// or until it expires, an expired lease can be acquired by anyone.
+type Lease struct {
+	Name         string    `firestore:"-"`
+	Owner        string    `firestore:"owner"`
+	AcquiredDate time.Time `firestore:"acquiredDate"`
+	ExpireDate   time.Time `firestore:"expireDate"`
+}
+
+// AcquireLease acquires the lease name for the lease TTL, or returns ErrLeaseHeld if another owner holds
+// it. Every acquisition has a new owner id, so that a lease is not shared by jobs of the same writer.
+// Expiry dates are set by the clock of the owner, so a lease is only taken over once it expired by the
+// lease clock skew too, and its owner gives it up that long before it expires, see keepLease.
+func (c *Client) AcquireLease(ctx context.Context, name string) (*Lease, error) {
+	owner, err := c.newLeaseOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	var lease *Lease
+	err = c.store.runTransaction(ctx, func(ctx context.Context, tx ops) error {
+		current, err := getLease(ctx, tx, name)
+		if err != nil && !errors.Is(err, ErrDocumentDoesNotExist) {
+			return err
+		}
+
+		now := c.store.now()
+		if current != nil && current.ExpireDate.Add(c.leaseClockSkew).After(now) {
+			return fmt.Errorf("%w: %s is held by %s until %s", ErrLeaseHeld, name, current.Owner, current.ExpireDate)
+		}
+
+		lease = &Lease{Name: name, Owner: owner, AcquiredDate: now, ExpireDate: now.Add(c.leaseTTL)}
+		return tx.set(ctx, leasePath(name), lease, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// RenewLease extends the lease by the lease TTL, or returns ErrLeaseLost if it was released or acquired
+// by another owner.
+func (c *Client) RenewLease(ctx context.Context, lease *Lease) error {
+	var expireDate time.Time
+	err := c.store.runTransaction(ctx, func(ctx context.Context, tx ops) error {
+		current, err := getLease(ctx, tx, lease.Name)
+		if errors.Is(err, ErrDocumentDoesNotExist) || (err == nil && current.Owner != lease.Owner) {
+			return fmt.Errorf("%w: %s", ErrLeaseLost, lease.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		expireDate = c.store.now().Add(c.leaseTTL)
+		return tx.set(ctx, leasePath(lease.Name), map[string]interface{}{leaseExpireDateKey: expireDate}, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	lease.ExpireDate = expireDate
+	return nil
+}
+
+// ReleaseLease deletes the lease unless another owner acquired it meanwhile.
+func (c *Client) ReleaseLease(ctx context.Context, lease *Lease) error {
+	return c.store.runTransaction(ctx, func(ctx context.Context, tx ops) error {
+		current, err := getLease(ctx, tx, lease.Name)
+		if errors.Is(err, ErrDocumentDoesNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if current.Owner != lease.Owner {
+			return nil
+		}
+
+		return tx.delete(ctx, leasePath(lease.Name))
+	})
+}
+
+// WithLease runs fn while holding the lease name and releases it afterwards. It returns ErrLeaseHeld
+// without running fn if another owner holds the lease. The lease is renewed every third of the lease
+// TTL, if it is lost the context of fn is cancelled and WithLease returns ErrLeaseLost. Leases are
+// written in dry-run mode too, so that dry runs do not overlap real jobs.
+func (c *Client) WithLease(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	lease, err := c.AcquireLease(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	renewing := make(chan struct{})
+	go func() {
+		defer close(renewing)
+		c.keepLease(leaseCtx, lease, cancel)
+	}()
+
+	err = fn(leaseCtx)
+	cancel(nil)
+	<-renewing
+
+	if err := c.ReleaseLease(context.WithoutCancel(ctx), lease); err != nil {
+		c.logger.WithError(err).WithField("lease", name).Warn("fs lease release failed, it expires at its TTL")
+	}
+
+	if cause := context.Cause(leaseCtx); errors.Is(cause, ErrLeaseLost) {
+		if err != nil {
+			return fmt.Errorf("%w: %w", cause, err)
+		}
+		return cause
+	}
+
+	return err
+}
+
+// RefreshBangumiToken refreshes the stored Bangumi token under TokenLeaseName and stores the new one,
+// so that overlapping jobs never spend the same refresh token twice. The token is read after the lease
+// is acquired. In dry-run mode the stored token is returned without refreshing it, since the new one
+// could not be stored, unless WithDryRunTokenWrites is set.
+func (c *Client) RefreshBangumiToken(ctx context.Context, refresher bangumi.TokenRefresher) (*model.FirestoreBangumiToken, error) {
+	var token *model.FirestoreBangumiToken
+	err := c.WithLease(ctx, TokenLeaseName, func(ctx context.Context) error {
+		current, err := c.GetBangumiToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		if c.dryRun && !c.dryRunTokenWrites {
+			c.logger.WithField("lease", TokenLeaseName).Info("fs dry run: skipped token refresh")
+			token = current
+			return nil
+		}
+
+		resp, err := refresher.RefreshAccessToken(ctx, *current)
+		if err != nil {
+			return err
+		}
+
+		if err := c.saveBangumiToken(ctx, resp.AccessToken, resp.RefreshToken); err != nil {
+			return err
+		}
+
+		current.AccessToken, current.RefreshToken = resp.AccessToken, resp.RefreshToken
+		token = current
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// keepLease renews the lease until ctx is done. It cancels ctx with ErrLeaseLost when the lease was
+// taken over, or when renewals keep failing until the lease clock skew before the lease expires.
+func (c *Client) keepLease(ctx context.Context, lease *Lease, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(c.leaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := c.RenewLease(ctx, lease)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrLeaseLost):
+			cancel(err)
+			return
+		case ctx.Err() != nil:
+			return
+		case !c.store.now().Before(lease.ExpireDate.Add(-c.leaseClockSkew)):
+			cancel(fmt.Errorf("%w: %s expired: %w", ErrLeaseLost, lease.Name, err))
+			return
+		default:
+			c.logger.WithError(err).WithField("lease", lease.Name).Warn("fs lease renewal failed, retrying")
+		}
+	}
+}
+
+// newLeaseOwner returns a unique owner id naming the writer and the host, e.g. "job@host-1a2b3c4d".
+func (c *Client) newLeaseOwner() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	host, _ := os.Hostname()
+
+	return fmt.Sprintf("%s@%s-%s", c.writer, host, hex.EncodeToString(b)), nil
+}
+
+func getLease(ctx context.Context, o ops, name string) (*Lease, error) {
+	doc, err := o.get(ctx, leasePath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var lease Lease
+	if err := doc.dataTo(&lease); err != nil {
+		return nil, err
+	}
+	lease.Name = name
+
+	return &lease, nil
+}
+
+func leasePath(name string) string {
+	return DocumentPath(LeaseCollectionKey, name)
+}
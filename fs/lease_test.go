@@ -0,0 +1,222 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/bangumi/bangumifakes"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("fs lease unit tests", func() {
+	var (
+		ctx context.Context
+		m   *Memory
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+		m = NewMemory(WithWriter("job"))
+		m.SetClock(func() time.Time { return now })
+	})
+
+	It("takes over expired leases", func() {
+		first, err := m.AcquireLease(ctx, "season")
+		Expect(err).To(BeNil())
+		Expect(first.Owner).To(HavePrefix("job@"))
+		Expect(first.ExpireDate).To(Equal(now.Add(DefaultLeaseTTL)))
+
+		now = now.Add(DefaultLeaseTTL)
+		_, err = m.AcquireLease(ctx, "season")
+		Expect(errors.Is(err, ErrLeaseHeld)).To(BeTrue())
+
+		now = now.Add(DefaultLeaseClockSkew)
+		second, err := m.AcquireLease(ctx, "season")
+		Expect(err).To(BeNil())
+		Expect(second.Owner).ToNot(Equal(first.Owner))
+
+		Expect(errors.Is(m.RenewLease(ctx, first), ErrLeaseLost)).To(BeTrue())
+
+		Expect(m.ReleaseLease(ctx, first)).To(Succeed())
+		_, ok := m.Document(DocumentPath(LeaseCollectionKey, "season"))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("extends renewed leases", func() {
+		lease, err := m.AcquireLease(ctx, "season")
+		Expect(err).To(BeNil())
+
+		now = now.Add(DefaultLeaseTTL / 2)
+		Expect(m.RenewLease(ctx, lease)).To(Succeed())
+		Expect(lease.ExpireDate).To(Equal(now.Add(DefaultLeaseTTL)))
+
+		now = now.Add(DefaultLeaseTTL / 2)
+		_, err = m.AcquireLease(ctx, "season")
+		Expect(errors.Is(err, ErrLeaseHeld)).To(BeTrue())
+	})
+
+	Describe("WithLease", func() {
+		It("runs fn holding the lease and releases it", func() {
+			ran := false
+			err := m.WithLease(ctx, SeasonLeaseName, func(ctx context.Context) error {
+				ran = true
+
+				_, err := m.AcquireLease(ctx, SeasonLeaseName)
+				Expect(errors.Is(err, ErrLeaseHeld)).To(BeTrue())
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(ran).To(BeTrue())
+			_, ok := m.Document(DocumentPath(LeaseCollectionKey, SeasonLeaseName))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("does not run fn while another owner holds the lease", func() {
+			_, err := m.AcquireLease(ctx, SeasonLeaseName)
+			Expect(err).To(BeNil())
+
+			err = m.WithLease(ctx, SeasonLeaseName, func(ctx context.Context) error {
+				Fail("fn must not run")
+				return nil
+			})
+
+			Expect(errors.Is(err, ErrLeaseHeld)).To(BeTrue())
+		})
+
+		It("cancels fn when the lease is lost", func() {
+			m = NewMemory(WithLeaseTTL(30 * time.Millisecond))
+
+			err := m.WithLease(ctx, SeasonLeaseName, func(ctx context.Context) error {
+				Expect(m.Seed(DocumentPath(LeaseCollectionKey, SeasonLeaseName), Lease{Owner: "other"})).To(Succeed())
+
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+			Expect(errors.Is(err, ErrLeaseLost)).To(BeTrue())
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+
+			doc, ok := m.Document(DocumentPath(LeaseCollectionKey, SeasonLeaseName))
+			Expect(ok).To(BeTrue())
+			Expect(doc["owner"]).To(Equal("other"))
+		})
+
+		It("keeps the lease while fn runs longer than the TTL", func() {
+			m = NewMemory(WithLeaseTTL(30 * time.Millisecond))
+
+			err := m.WithLease(ctx, SeasonLeaseName, func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(100 * time.Millisecond):
+					return nil
+				}
+			})
+
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Describe("PublishSeason", func() {
+		merge := func(existing []model.FirestoreSeasonIndexItem) []model.FirestoreSeasonIndexItem {
+			return append([]model.FirestoreSeasonIndexItem{{ID: "202504"}}, existing...)
+		}
+
+		It("publishes the season and the merged index under the lease", func() {
+			Expect(m.UpdateSeasonIndex(ctx, []model.FirestoreSeasonIndexItem{{ID: "202501"}})).Error().To(Succeed())
+
+			diffs, err := m.PublishSeason(ctx, "202504", []model.FirestoreSeasonSubject{{ID: 1}}, merge)
+
+			Expect(err).To(BeNil())
+			Expect(diffs).To(HaveLen(2))
+			Expect(diffs[0].Path).To(Equal("season/202504"))
+
+			index, err := m.GetSeasonIndex(ctx)
+			Expect(err).To(BeNil())
+			Expect(index.Data).To(Equal([]model.FirestoreSeasonIndexItem{{ID: "202504"}, {ID: "202501"}}))
+
+			_, ok := m.Document(DocumentPath(LeaseCollectionKey, SeasonLeaseName))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("does not publish while another owner holds the lease", func() {
+			_, err := m.AcquireLease(ctx, SeasonLeaseName)
+			Expect(err).To(BeNil())
+			writes := len(m.Writes())
+
+			_, err = m.PublishSeason(ctx, "202504", []model.FirestoreSeasonSubject{{ID: 1}}, merge)
+
+			Expect(errors.Is(err, ErrLeaseHeld)).To(BeTrue())
+			Expect(m.Writes()).To(HaveLen(writes))
+		})
+	})
+
+	Describe("RefreshBangumiToken", func() {
+		var refresher *bangumifakes.FakeTokenRefresher
+
+		BeforeEach(func() {
+			Expect(m.Seed(DocumentPath(TokenCollectionKey, TokenCollectionBangumiDocKey), model.FirestoreBangumiToken{
+				AccessToken:  "access",
+				RefreshToken: "refresh",
+				ClientID:     "client",
+			})).To(Succeed())
+
+			refresher = &bangumifakes.FakeTokenRefresher{}
+			refresher.RefreshAccessTokenReturns(&model.BangumiOAuthResponse{AccessToken: "access2", RefreshToken: "refresh2"}, nil)
+		})
+
+		It("refreshes and stores the token under the lease", func() {
+			token, err := m.RefreshBangumiToken(ctx, refresher)
+
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access2"))
+			Expect(token.ClientID).To(Equal("client"))
+
+			_, sent := refresher.RefreshAccessTokenArgsForCall(0)
+			Expect(sent.RefreshToken).To(Equal("refresh"))
+
+			stored, err := m.GetBangumiToken(ctx)
+			Expect(err).To(BeNil())
+			Expect(stored.RefreshToken).To(Equal("refresh2"))
+		})
+
+		It("does not refresh while another job holds the lease", func() {
+			_, err := m.AcquireLease(ctx, TokenLeaseName)
+			Expect(err).To(BeNil())
+
+			_, err = m.RefreshBangumiToken(ctx, refresher)
+
+			Expect(errors.Is(err, ErrLeaseHeld)).To(BeTrue())
+			Expect(refresher.RefreshAccessTokenCallCount()).To(Equal(0))
+		})
+
+		It("does not refresh in dry-run mode", func() {
+			m.dryRun = true
+
+			token, err := m.RefreshBangumiToken(ctx, refresher)
+
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access"))
+			Expect(refresher.RefreshAccessTokenCallCount()).To(Equal(0))
+		})
+
+		It("refreshes and stores the token in dry-run mode with token writes", func() {
+			m.dryRun, m.dryRunTokenWrites = true, true
+
+			token, err := m.RefreshBangumiToken(ctx, refresher)
+
+			Expect(err).To(BeNil())
+			Expect(token.AccessToken).To(Equal("access2"))
+
+			stored, err := m.GetBangumiToken(ctx)
+			Expect(err).To(BeNil())
+			Expect(stored.RefreshToken).To(Equal("refresh2"))
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package fs
+
+import (
+	"context"
+	"github.com/bangumilite/bangumilite-component/mailer"
+	"github.com/bangumilite/bangumilite-component/model"
+	"time"
+)
+
+// listenBackoff and listenMaxBackoff bound the delay before a broken listener reconnects, the delay
+// doubles with every failure in a row.
+var (
+	listenBackoff    = time.Second
+	listenMaxBackoff = time.Minute
+)
+
+// ListenBangumiToken calls fn with the current token and again whenever another job rotates it, until
+// ctx is done or fn fails. Broken listeners reconnect, missing documents are not delivered.
+func (c *Client) ListenBangumiToken(ctx context.Context, fn func(token *model.FirestoreBangumiToken) error) error {
+	return listen(ctx, c, DocumentPath(TokenCollectionKey, TokenCollectionBangumiDocKey), fn)
+}
+
+// ListenMailgunConfig calls fn with the current config and again whenever it changes, see ListenBangumiToken.
+func (c *Client) ListenMailgunConfig(ctx context.Context, fn func(config *mailer.MailgunConfig) error) error {
+	return listen(ctx, c, DocumentPath(TokenCollectionKey, MailgunDocumentKey), fn)
+}
+
+// WatchBangumiToken delivers the token on the returned channel like ListenBangumiToken, the channel is
+// closed when ctx is done.
+func (c *Client) WatchBangumiToken(ctx context.Context) <-chan *model.FirestoreBangumiToken {
+	return watch[model.FirestoreBangumiToken](ctx, c, DocumentPath(TokenCollectionKey, TokenCollectionBangumiDocKey))
+}
+
+// WatchMailgunConfig delivers the config on the returned channel like ListenMailgunConfig, the channel is
+// closed when ctx is done.
+func (c *Client) WatchMailgunConfig(ctx context.Context) <-chan *mailer.MailgunConfig {
+	return watch[mailer.MailgunConfig](ctx, c, DocumentPath(TokenCollectionKey, MailgunDocumentKey))
+}
+
+func listen[T any](ctx context.Context, c *Client, path string, fn func(*T) error) error {
+	backoff := listenBackoff
+
+	for {
+		var stopped error
+		err := c.store.watch(ctx, path, func(doc *document) error {
+			backoff = listenBackoff
+
+			if doc == nil {
+				return nil
+			}
+
+			var value T
+			if err := doc.dataTo(&value); err != nil {
+				c.logger.WithError(err).WithField("path", path).Warn("fs listener skipped an undecodable document")
+				return nil
+			}
+
+			stopped = fn(&value)
+			return stopped
+		})
+
+		if stopped != nil {
+			return stopped
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		c.logger.WithError(err).WithField("path", path).Warnf("fs listener broke, reconnecting in %s", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, listenMaxBackoff)
+	}
+}
+
+func watch[T any](ctx context.Context, c *Client, path string) <-chan *T {
+	ch := make(chan *T)
+
+	go func() {
+		defer close(ch)
+
+		_ = listen(ctx, c, path, func(value *T) error {
+			select {
+			case ch <- value:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return ch
+}
@@ -0,0 +1,108 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/mailer"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus/hooks/test"
+	"sync"
+	"time"
+)
+
+// flakyStore breaks the first failures watches.
+type flakyStore struct {
+	store
+
+	mu       sync.Mutex
+	failures int
+}
+
+func (s *flakyStore) watch(ctx context.Context, path string, fn func(doc *document) error) error {
+	s.mu.Lock()
+	fail := s.failures > 0
+	s.failures--
+	s.mu.Unlock()
+
+	if fail {
+		return errors.New("stream broken")
+	}
+
+	return s.store.watch(ctx, path, fn)
+}
+
+var _ = Describe("fs listener unit tests", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		m      *Memory
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		m = NewMemory()
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("delivers a document once it exists", func() {
+		configs := m.WatchMailgunConfig(ctx)
+		Consistently(configs, 50*time.Millisecond).ShouldNot(Receive())
+
+		Expect(m.Seed(DocumentPath(TokenCollectionKey, MailgunDocumentKey), mailer.MailgunConfig{Domain: "domain"})).To(Succeed())
+
+		var config *mailer.MailgunConfig
+		Eventually(configs).Should(Receive(&config))
+		Expect(config.Domain).To(Equal("domain"))
+	})
+
+	It("delivers the current token first", func() {
+		Expect(m.UpdateBangumiToken(ctx, "access", "refresh")).To(Succeed())
+
+		var token *model.FirestoreBangumiToken
+		Eventually(m.WatchBangumiToken(ctx)).Should(Receive(&token))
+		Expect(token.RefreshToken).To(Equal("refresh"))
+	})
+
+	It("returns the error of the callback", func() {
+		Expect(m.UpdateBangumiToken(ctx, "access", "refresh")).To(Succeed())
+		stop := errors.New("stop")
+
+		err := m.ListenBangumiToken(ctx, func(token *model.FirestoreBangumiToken) error {
+			return stop
+		})
+
+		Expect(err).To(Equal(stop))
+	})
+
+	It("returns when the context is cancelled", func() {
+		done := make(chan error)
+		go func() {
+			done <- m.ListenBangumiToken(ctx, func(token *model.FirestoreBangumiToken) error {
+				return nil
+			})
+		}()
+
+		cancel()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+	})
+
+	It("reconnects broken listeners", func() {
+		backoff := listenBackoff
+		listenBackoff = time.Millisecond
+		defer func() { listenBackoff = backoff }()
+
+		logger, hook := test.NewNullLogger()
+		c := newMemoryConfig(WithLogger(logger)).newClient(&flakyStore{store: m.mem, failures: 2})
+		Expect(m.UpdateBangumiToken(ctx, "access", "refresh")).To(Succeed())
+
+		var token *model.FirestoreBangumiToken
+		Eventually(c.WatchBangumiToken(ctx)).Should(Receive(&token))
+		Expect(token.AccessToken).To(Equal("access"))
+		Expect(hook.AllEntries()).To(HaveLen(2))
+	})
+})
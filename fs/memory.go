@@ -0,0 +1,559 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Write is a document write recorded by Memory, with server timestamps resolved to the commit time.
+type Write struct {
+	Path   string
+	Data   map[string]interface{}
+	Merge  bool
+	Delete bool
+	Time   time.Time
+}
+
+// Memory is a Repository that keeps documents in memory and records every write, so that service
+// tests can assert exactly what would be published. It is safe for concurrent use.
+type Memory struct {
+	*Client
+	mem *memoryStore
+}
+
+var _ Repository = (*Memory)(nil)
+
+// NewMemory returns an empty Memory, connection options are ignored. Unlike New it does not read the
+// environment, the dry-run mode is only enabled by WithDryRun.
+func NewMemory(opts ...Option) *Memory {
+	s := &memoryStore{
+		docs:  map[string]map[string]interface{}{},
+		clock: time.Now,
+	}
+
+	return &Memory{
+		Client: newMemoryConfig(opts...).newClient(s),
+		mem:    s,
+	}
+}
+
+// SetClock sets the clock used for simulated server timestamps.
+func (m *Memory) SetClock(now func() time.Time) {
+	m.mem.clockMu.Lock()
+	defer m.mem.clockMu.Unlock()
+
+	m.mem.clock = now
+}
+
+// Seed stores a document without recording a write, data is a struct or a map.
+func (m *Memory) Seed(path string, data interface{}) error {
+	m.mem.mu.Lock()
+	defer m.mem.mu.Unlock()
+
+	doc, err := m.mem.encodeDocument(data, m.mem.now())
+	if err != nil {
+		return err
+	}
+
+	m.mem.docs[path] = doc
+	m.mem.notify(path)
+	return nil
+}
+
+// Document returns a copy of the stored document data in its generic form.
+func (m *Memory) Document(path string) (map[string]interface{}, bool) {
+	m.mem.mu.Lock()
+	defer m.mem.mu.Unlock()
+
+	doc, ok := m.mem.docs[path]
+	if !ok {
+		return nil, false
+	}
+
+	return clone(doc).(map[string]interface{}), true
+}
+
+// Paths returns the paths of every stored document in order.
+func (m *Memory) Paths() []string {
+	m.mem.mu.Lock()
+	defer m.mem.mu.Unlock()
+
+	paths := make([]string, 0, len(m.mem.docs))
+	for path := range m.mem.docs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// Writes returns the recorded writes in order.
+func (m *Memory) Writes() []Write {
+	m.mem.mu.Lock()
+	defer m.mem.mu.Unlock()
+
+	writes := make([]Write, len(m.mem.writes))
+	for i, w := range m.mem.writes {
+		w.Data = clone(w.Data).(map[string]interface{})
+		writes[i] = w
+	}
+
+	return writes
+}
+
+// Reset drops every document and recorded write.
+func (m *Memory) Reset() {
+	m.mem.mu.Lock()
+	defer m.mem.mu.Unlock()
+
+	m.mem.docs = map[string]map[string]interface{}{}
+	m.mem.writes = nil
+
+	for path := range m.mem.watchers {
+		m.mem.notify(path)
+	}
+}
+
+type memoryStore struct {
+	mu     sync.Mutex
+	docs   map[string]map[string]interface{}
+	writes []Write
+
+	// watchers are signalled after every committed change of the document at their path.
+	watchers map[string]map[chan struct{}]bool
+
+	// clockMu guards clock separately, so that now can be called with mu held.
+	clockMu sync.Mutex
+	clock   func() time.Time
+}
+
+func (s *memoryStore) get(ctx context.Context, path string) (document, error) {
+	if err := ctx.Err(); err != nil {
+		return document{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.getLocked(path)
+}
+
+func (s *memoryStore) getLocked(path string) (document, error) {
+	doc, ok := s.docs[path]
+	if !ok {
+		return document{}, ErrDocumentDoesNotExist
+	}
+
+	_, id, _ := cutLast(path)
+	return memoryDocument(id, clone(doc).(map[string]interface{})), nil
+}
+
+// memoryDocument decodes documents of the memory store with the codec.
+func memoryDocument(id string, data map[string]interface{}) document {
+	return document{id: id, data: data, dataTo: func(v interface{}) error {
+		return decode(data, v)
+	}}
+}
+
+func (s *memoryStore) set(ctx context.Context, path string, data interface{}, merge bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.write(path, data, merge, s.now()); err != nil {
+		return err
+	}
+
+	s.notify(path)
+	return nil
+}
+
+func (s *memoryStore) setAll(ctx context.Context, docs map[string]interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := make([]string, 0, len(docs))
+	for path := range docs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	now := s.now()
+	for _, path := range paths {
+		if err := s.write(path, docs[path], false, now); err != nil {
+			return err
+		}
+		s.notify(path)
+	}
+
+	return nil
+}
+
+func (s *memoryStore) delete(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(path, s.now())
+	s.notify(path)
+	return nil
+}
+
+// remove deletes a document with the lock held and records it.
+func (s *memoryStore) remove(path string, now time.Time) {
+	delete(s.docs, path)
+	s.writes = append(s.writes, Write{
+		Path:   path,
+		Delete: true,
+		Time:   now,
+	})
+}
+
+func (s *memoryStore) query(ctx context.Context, collection string, fields []string, filters ...filter) ([]document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queryLocked(collection, fields, filters...)
+}
+
+func (s *memoryStore) queryLocked(collection string, fields []string, filters ...filter) ([]document, error) {
+	paths := make([]string, 0)
+	for path := range s.docs {
+		if parent, _, ok := cutLast(path); ok && parent == collection {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	results := make([]document, 0, len(paths))
+	for _, path := range paths {
+		doc := s.docs[path]
+
+		match := true
+		for _, f := range filters {
+			ok, err := f.match(doc)
+			if err != nil {
+				return nil, err
+			}
+			match = match && ok
+		}
+
+		if match {
+			_, id, _ := cutLast(path)
+			results = append(results, memoryDocument(id, project(doc, fields)))
+		}
+	}
+
+	return results, nil
+}
+
+// runTransaction holds the lock while fn runs and applies its writes at a single commit time,
+// restoring the previous state if any write fails.
+func (s *memoryStore) runTransaction(ctx context.Context, fn func(ctx context.Context, tx ops) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &memoryTransaction{s: s}
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	docs := make(map[string]map[string]interface{}, len(s.docs))
+	for path, doc := range s.docs {
+		docs[path] = doc
+	}
+	writes := len(s.writes)
+
+	now := s.now()
+	for _, w := range tx.writes {
+		if w.Delete {
+			s.remove(w.Path, now)
+			continue
+		}
+
+		if err := s.write(w.Path, w.data, w.Merge, now); err != nil {
+			s.docs, s.writes = docs, s.writes[:writes]
+			return err
+		}
+	}
+
+	for _, w := range tx.writes {
+		s.notify(w.Path)
+	}
+
+	return nil
+}
+
+// watch signals a buffered channel on every change and reads the document when it is drained, so
+// that a slow fn only sees the latest state like a Firestore listener.
+func (s *memoryStore) watch(ctx context.Context, path string, fn func(doc *document) error) error {
+	changed := make(chan struct{}, 1)
+	changed <- struct{}{}
+
+	s.mu.Lock()
+	if s.watchers == nil {
+		s.watchers = map[string]map[chan struct{}]bool{}
+	}
+	if s.watchers[path] == nil {
+		s.watchers[path] = map[chan struct{}]bool{}
+	}
+	s.watchers[path][changed] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.watchers[path], changed)
+		if len(s.watchers[path]) == 0 {
+			delete(s.watchers, path)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+
+		var doc *document
+		d, err := s.get(ctx, path)
+		if err == nil {
+			doc = &d
+		} else if !errors.Is(err, ErrDocumentDoesNotExist) {
+			return err
+		}
+
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// notify signals the watchers of path with the lock held, without blocking.
+func (s *memoryStore) notify(path string) {
+	for changed := range s.watchers[path] {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *memoryStore) now() time.Time {
+	s.clockMu.Lock()
+	defer s.clockMu.Unlock()
+
+	return s.clock()
+}
+
+func (s *memoryStore) close() error {
+	return nil
+}
+
+// write applies a write with the lock held and records it.
+func (s *memoryStore) write(path string, data interface{}, merge bool, now time.Time) error {
+	doc, err := s.encodeDocument(data, now)
+	if err != nil {
+		return err
+	}
+
+	if existing, ok := s.docs[path]; merge && ok {
+		merged := clone(existing).(map[string]interface{})
+		mergeFields(merged, doc, data)
+		s.docs[path] = merged
+	} else {
+		deleteFields(doc)
+		s.docs[path] = doc
+	}
+
+	s.writes = append(s.writes, Write{
+		Path:  path,
+		Data:  clone(doc).(map[string]interface{}),
+		Merge: merge,
+		Time:  now,
+	})
+
+	return nil
+}
+
+// encodeDocument encodes a struct or map into document data, resolving server timestamps to now.
+func (s *memoryStore) encodeDocument(data interface{}, now time.Time) (map[string]interface{}, error) {
+	encoded, err := encode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := encoded.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("fs: document data must be a struct or map, got %T", data)
+	}
+
+	for k, v := range doc {
+		if v == firestore.ServerTimestamp {
+			doc[k] = now.Truncate(time.Microsecond)
+		}
+	}
+
+	return doc, nil
+}
+
+// mergeFields merges src into dst like firestore.MergeAll: nested maps in the original data are merged
+// field by field, any other value replaces the existing one and firestore.Delete removes the field.
+func mergeFields(dst map[string]interface{}, src map[string]interface{}, original interface{}) {
+	originalMap, _ := original.(map[string]interface{})
+
+	for k, v := range src {
+		if v == firestore.Delete {
+			delete(dst, k)
+			continue
+		}
+
+		nested, isMap := originalMap[k].(map[string]interface{})
+		existing, hasMap := dst[k].(map[string]interface{})
+		if isMap && hasMap {
+			mergeFields(existing, v.(map[string]interface{}), nested)
+			continue
+		}
+
+		dst[k] = v
+	}
+}
+
+func deleteFields(doc map[string]interface{}) {
+	for k, v := range doc {
+		if v == firestore.Delete {
+			delete(doc, k)
+		}
+	}
+}
+
+func (f filter) match(doc map[string]interface{}) (bool, error) {
+	var v interface{} = doc
+	for _, part := range strings.Split(f.field, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false, nil
+		}
+		if v, ok = m[part]; !ok {
+			return false, nil
+		}
+	}
+
+	value, err := encode(f.value)
+	if err != nil {
+		return false, err
+	}
+
+	c, ok := compareValues(v, value)
+	if !ok {
+		return false, nil
+	}
+
+	switch f.op {
+	case "==":
+		return c == 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	}
+
+	return false, fmt.Errorf("fs: unsupported query operator %q", f.op)
+}
+
+// project copies the listed fields of doc, or all of them if fields is nil.
+func project(doc map[string]interface{}, fields []string) map[string]interface{} {
+	if fields == nil {
+		return clone(doc).(map[string]interface{})
+	}
+
+	out := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if v, ok := doc[f]; ok {
+			out[f] = clone(v)
+		}
+	}
+	return out
+}
+
+// cutLast splits a path at its last slash.
+func cutLast(path string) (string, string, bool) {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "", path, false
+	}
+	return path[:i], path[i+1:], true
+}
+
+// memoryTransaction reads from the store and stages writes until the transaction commits.
+type memoryTransaction struct {
+	s      *memoryStore
+	writes []stagedWrite
+}
+
+type stagedWrite struct {
+	Write
+	data interface{}
+}
+
+var errReadAfterWrite = errors.New("fs: read after write in transaction")
+
+func (t *memoryTransaction) get(ctx context.Context, path string) (document, error) {
+	if len(t.writes) > 0 {
+		return document{}, errReadAfterWrite
+	}
+
+	return t.s.getLocked(path)
+}
+
+func (t *memoryTransaction) set(ctx context.Context, path string, data interface{}, merge bool) error {
+	t.writes = append(t.writes, stagedWrite{Write: Write{Path: path, Merge: merge}, data: data})
+	return nil
+}
+
+func (t *memoryTransaction) delete(ctx context.Context, path string) error {
+	t.writes = append(t.writes, stagedWrite{Write: Write{Path: path, Delete: true}})
+	return nil
+}
+
+func (t *memoryTransaction) query(ctx context.Context, collection string, fields []string, filters ...filter) ([]document, error) {
+	if len(t.writes) > 0 {
+		return nil, errReadAfterWrite
+	}
+
+	return t.s.queryLocked(collection, fields, filters...)
+}
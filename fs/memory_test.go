@@ -0,0 +1,210 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/bangumilite/bangumilite-component/season"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sync"
+	"time"
+)
+
+var _ = Describe("fs memory repository", func() {
+	describeRepository(func() Repository { return NewMemory() })
+})
+
+var _ = Describe("fs memory unit tests", func() {
+	var (
+		ctx context.Context
+		m   *Memory
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+		m = NewMemory()
+		m.SetClock(func() time.Time { return now })
+	})
+
+	It("records writes with simulated server timestamps", func() {
+		subjects := []model.FirestoreSubject{{ID: 1, Name: "name"}}
+
+		Expect(m.UpdateTrendingSubjects(ctx, "2", subjects)).Error().To(Succeed())
+
+		writes := m.Writes()
+		Expect(writes).To(HaveLen(1))
+		Expect(writes[0].Path).To(Equal("trending/2"))
+		Expect(writes[0].Merge).To(BeTrue())
+		Expect(writes[0].Time).To(Equal(now))
+		Expect(writes[0].Data[FirebaseLastUpdatedTimestampKey]).To(Equal(now))
+		Expect(writes[0].Data["data"]).To(HaveLen(1))
+	})
+
+	It("reads back the simulated last updated date", func() {
+		Expect(m.UpdateRelatedSubjects(ctx, 1, []model.FirestoreSubject{{ID: 2}})).Error().To(Succeed())
+
+		got, err := m.GetRelatedSubjects(ctx, 1)
+		Expect(err).To(BeNil())
+		Expect(got.LastUpdatedDate).To(Equal(now))
+	})
+
+	It("merges top level fields into existing documents", func() {
+		Expect(m.Seed("token/bangumi", model.FirestoreBangumiToken{
+			AccessToken: "old",
+			ClientID:    "client",
+		})).To(Succeed())
+
+		Expect(m.UpdateBangumiToken(ctx, "access", "refresh")).To(Succeed())
+
+		got, err := m.GetBangumiToken(ctx)
+		Expect(err).To(BeNil())
+		Expect(got.AccessToken).To(Equal("access"))
+		Expect(got.RefreshToken).To(Equal("refresh"))
+		Expect(got.ClientID).To(Equal("client"))
+	})
+
+	It("does not share stored data with callers", func() {
+		Expect(m.UpdateSeasonIndex(ctx, []model.FirestoreSeasonIndexItem{{ID: "202504"}})).Error().To(Succeed())
+
+		doc, ok := m.Document("season/index")
+		Expect(ok).To(BeTrue())
+		doc["data"] = nil
+
+		got, err := m.GetSeasonIndex(ctx)
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(HaveLen(1))
+	})
+
+	It("round trips stored seasons", func() {
+		type stored struct {
+			Season model.SeasonID `firestore:"season"`
+		}
+		Expect(m.Seed("seasons/current", stored{Season: season.New(now).Firestore()})).To(Succeed())
+
+		doc, err := m.mem.get(ctx, "seasons/current")
+		Expect(err).To(BeNil())
+		Expect(doc.data["season"]).To(Equal("202504"))
+
+		var got stored
+		Expect(doc.dataTo(&got)).To(Succeed())
+
+		s, err := season.FromFirestore(got.Season)
+		Expect(err).To(BeNil())
+		Expect(s).To(Equal(season.New(now)))
+	})
+
+	Describe("versions", func() {
+		path := DocumentPath(SeasonCollectionKey, "202504")
+
+		publish := func(id int) {
+			now = now.Add(time.Hour)
+			Expect(m.UpdateSeasonalSubjects(ctx, "202504", []model.FirestoreSeasonSubject{{ID: id}})).Error().To(Succeed())
+		}
+
+		It("keeps the last versions with writer metadata", func() {
+			m = NewMemory(WithWriter("job"), WithHistoryLimit(2))
+			m.SetClock(func() time.Time { return now })
+			first := now.Add(time.Hour)
+
+			for id := 1; id <= 4; id++ {
+				publish(id)
+			}
+
+			versions, err := m.ListVersions(ctx, path)
+			Expect(err).To(BeNil())
+			Expect(versions).To(HaveLen(2))
+			Expect(versions[0].PublishedDate).To(Equal(first.Add(2 * time.Hour)))
+			Expect(versions[0].Writer).To(Equal("job"))
+			Expect(versions[0].ArchivedBy).To(Equal("job"))
+			Expect(versions[0].ArchivedDate).To(Equal(now))
+			Expect(versions[1].PublishedDate).To(Equal(first.Add(time.Hour)))
+
+			Expect(m.Rollback(ctx, path, versions[1].ID)).To(Succeed())
+
+			got, err := m.GetSeasonalSubjects(ctx, "202504")
+			Expect(err).To(BeNil())
+			Expect(got.Data[0].ID).To(Equal(2))
+			Expect(got.LastUpdatedDate).To(Equal(now))
+		})
+
+		It("does not archive with a zero history limit", func() {
+			m = NewMemory(WithHistoryLimit(0))
+
+			publish(1)
+			publish(2)
+
+			versions, err := m.ListVersions(ctx, path)
+			Expect(err).To(BeNil())
+			Expect(versions).To(BeEmpty())
+		})
+	})
+
+	Describe("Publish", func() {
+		It("records the writes of a batch at one commit time", func() {
+			batch := NewBatch().
+				UpdateSeasonalSubjects("202504", []model.FirestoreSeasonSubject{{ID: 1}}).
+				UpdateSeasonIndex([]model.FirestoreSeasonIndexItem{{ID: "202504"}})
+
+			_, err := m.Publish(ctx, batch)
+			Expect(err).To(BeNil())
+
+			writes := m.Writes()
+			Expect(writes).To(HaveLen(2))
+			Expect(writes[0].Path).To(Equal("season/202504"))
+			Expect(writes[1].Path).To(Equal("season/index"))
+			Expect(writes[0].Data[FirebaseLastUpdatedTimestampKey]).To(Equal(now))
+			Expect(writes[1].Data[FirebaseLastUpdatedTimestampKey]).To(Equal(now))
+		})
+
+		It("writes nothing if any document fails", func() {
+			m = NewMemory(WithShardThreshold(500))
+			huge := []model.FirestoreSeasonSubject{{ID: 1, Summary: string(make([]byte, 1000))}}
+
+			batch := NewBatch().
+				UpdateSeasonIndex([]model.FirestoreSeasonIndexItem{{ID: "202504"}}).
+				UpdateSeasonalSubjects("202504", huge)
+
+			_, err := m.Publish(ctx, batch)
+			Expect(errors.Is(err, ErrDocumentTooLarge)).To(BeTrue())
+			Expect(m.Writes()).To(BeEmpty())
+			Expect(m.Paths()).To(BeEmpty())
+		})
+
+		It("rejects documents published twice", func() {
+			batch := NewBatch().
+				UpdateSeasonIndex(nil).
+				UpdateSeasonIndex(nil)
+
+			_, err := m.Publish(ctx, batch)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	It("is safe for concurrent use", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				Expect(m.UpdateRelatedSubjects(ctx, id, nil)).Error().To(Succeed())
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(m.Writes()).To(HaveLen(20))
+		Expect(m.Paths()).To(HaveLen(20))
+	})
+
+	It("fails on cancelled contexts", func() {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		Expect(m.UpdateSeasonIndex(cancelled, nil)).Error().ToNot(Succeed())
+		Expect(m.Writes()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,274 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+	"github.com/bangumilite/bangumilite-component/logger"
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// EmulatorHostEnv is read by New to connect to a local Firestore emulator, e.g. "localhost:8080".
+	EmulatorHostEnv = "FIRESTORE_EMULATOR_HOST"
+
+	DefaultCredentialsFile = "service_account.json"
+
+	// DefaultHistoryLimit is how many archived versions are kept per published document.
+	DefaultHistoryLimit = 10
+
+	// DryRunEnv enables the dry-run mode when set to a true value, e.g. "true" or "1".
+	DryRunEnv = "FS_DRY_RUN"
+
+	// DryRunDirEnv is the directory the dry-run mode writes payloads to.
+	DryRunDirEnv = "FS_DRY_RUN_DIR"
+
+	// MemoryWriter is the default writer of NewMemory.
+	MemoryWriter = "memory"
+)
+
+type config struct {
+	projectID       string
+	databaseID      string
+	credentialsFile string
+	credentialsJSON []byte
+	emulatorHost    string
+
+	writer         string
+	historyLimit   int
+	shardThreshold int
+	leaseTTL       time.Duration
+	leaseClockSkew time.Duration
+
+	dryRun            bool
+	dryRunDir         string
+	dryRunTokenWrites bool
+	logger            logrus.FieldLogger
+}
+
+type Option func(c *config)
+
+func WithProjectID(projectID string) Option {
+	return func(c *config) {
+		c.projectID = projectID
+	}
+}
+
+// WithDatabaseID selects a named database instead of the default one.
+func WithDatabaseID(databaseID string) Option {
+	return func(c *config) {
+		c.databaseID = databaseID
+	}
+}
+
+func WithCredentialsFile(path string) Option {
+	return func(c *config) {
+		c.credentialsFile = path
+		c.credentialsJSON = nil
+	}
+}
+
+func WithCredentialsJSON(json []byte) Option {
+	return func(c *config) {
+		c.credentialsJSON = json
+		c.credentialsFile = ""
+	}
+}
+
+// WithEmulator connects to the Firestore emulator at host without credentials. The firestore client
+// connects to EmulatorHostEnv itself when it is set, which takes precedence over host.
+func WithEmulator(host string) Option {
+	return func(c *config) {
+		c.emulatorHost = host
+	}
+}
+
+// WithWriter names the job recorded with archived versions, it defaults to the executable name.
+func WithWriter(writer string) Option {
+	return func(c *config) {
+		c.writer = writer
+	}
+}
+
+// WithHistoryLimit sets how many archived versions are kept per published document, 0 disables archiving.
+func WithHistoryLimit(limit int) Option {
+	return func(c *config) {
+		c.historyLimit = max(limit, 0)
+	}
+}
+
+// WithShardThreshold sets the estimated size in bytes above which a document's "data" array is split
+// into shards, 0 disables sharding.
+func WithShardThreshold(bytes int) Option {
+	return func(c *config) {
+		c.shardThreshold = max(min(bytes, MaxDocumentSize), 0)
+	}
+}
+
+// WithLeaseTTL sets how long a lease is held without renewal, it defaults to DefaultLeaseTTL.
+func WithLeaseTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		if ttl > 0 {
+			c.leaseTTL = ttl
+		}
+	}
+}
+
+// WithLeaseClockSkew sets the largest clock difference between jobs that leases allow for, it defaults to
+// DefaultLeaseClockSkew and is capped at a third of the lease TTL.
+func WithLeaseClockSkew(skew time.Duration) Option {
+	return func(c *config) {
+		c.leaseClockSkew = max(skew, 0)
+	}
+}
+
+// WithDryRun enables or disables the dry-run mode, overriding DryRunEnv. In dry-run mode every published
+// document is logged with its diff and estimated size instead of being written, and so is the Bangumi
+// token, reads still go to Firestore. Leases are still written, so that a dry run does not overlap other
+// jobs. RefreshBangumiToken returns the stored token without refreshing it, see WithDryRunTokenWrites.
+func WithDryRun(enabled bool) Option {
+	return func(c *config) {
+		c.dryRun = enabled
+	}
+}
+
+// WithDryRunDir writes the payload of every published document of the dry-run mode as a JSON file to
+// dir, overriding DryRunDirEnv.
+func WithDryRunDir(dir string) Option {
+	return func(c *config) {
+		c.dryRunDir = dir
+	}
+}
+
+// WithDryRunTokenWrites lets RefreshBangumiToken refresh and store the Bangumi token in dry-run mode.
+// A refresh invalidates the stored refresh token, so the new one must be stored for later jobs.
+func WithDryRunTokenWrites(enabled bool) Option {
+	return func(c *config) {
+		c.dryRunTokenWrites = enabled
+	}
+}
+
+// WithLogger sets the logger of the dry-run mode, it defaults to an info level logrus logger.
+func WithLogger(logger logrus.FieldLogger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// newConfig returns the defaults of New: the production project, application default credentials when
+// running in production, DefaultCredentialsFile otherwise, the emulator from EmulatorHostEnv and the
+// dry-run mode from DryRunEnv and DryRunDirEnv.
+func newConfig(opts ...Option) config {
+	dryRun, _ := strconv.ParseBool(os.Getenv(DryRunEnv))
+
+	c := defaultConfig()
+	c.emulatorHost = os.Getenv(EmulatorHostEnv)
+	c.writer = filepath.Base(os.Args[0])
+	c.dryRun = dryRun
+	c.dryRunDir = os.Getenv(DryRunDirEnv)
+
+	if os.Getenv(model.RunningEnvironment) != string(model.Production) {
+		c.credentialsFile = DefaultCredentialsFile
+	}
+
+	return c.apply(opts...)
+}
+
+// newMemoryConfig returns the defaults of NewMemory, which ignore the environment so that tests are
+// hermetic: the writer is MemoryWriter and the dry-run mode is only enabled by options.
+func newMemoryConfig(opts ...Option) config {
+	c := defaultConfig()
+	c.writer = MemoryWriter
+
+	return c.apply(opts...)
+}
+
+func defaultConfig() config {
+	return config{
+		projectID:      FirebaseProjectID,
+		historyLimit:   DefaultHistoryLimit,
+		shardThreshold: DefaultShardThreshold,
+		leaseTTL:       DefaultLeaseTTL,
+		leaseClockSkew: DefaultLeaseClockSkew,
+	}
+}
+
+func (c config) apply(opts ...Option) config {
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.logger == nil {
+		c.logger = logger.NewLogrus(logrus.InfoLevel)
+	}
+
+	return c
+}
+
+func (c config) newClient(s store) *Client {
+	return &Client{
+		store:             s,
+		writer:            c.writer,
+		historyLimit:      c.historyLimit,
+		shardThreshold:    c.shardThreshold,
+		leaseTTL:          c.leaseTTL,
+		leaseClockSkew:    min(c.leaseClockSkew, c.leaseTTL/3),
+		dryRun:            c.dryRun,
+		dryRunDir:         c.dryRunDir,
+		dryRunTokenWrites: c.dryRunTokenWrites,
+		logger:            c.logger,
+	}
+}
+
+func (c config) clientOptions() ([]option.ClientOption, error) {
+	// The firestore client dials the endpoint itself, so that Close also closes the connection.
+	if c.emulatorHost != "" {
+		return []option.ClientOption{
+			option.WithEndpoint(c.emulatorHost),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+			option.WithGRPCDialOption(grpc.WithPerRPCCredentials(emulatorCredentials{})),
+		}, nil
+	}
+
+	switch {
+	case len(c.credentialsJSON) > 0:
+		return []option.ClientOption{option.WithCredentialsJSON(c.credentialsJSON)}, nil
+	case c.credentialsFile != "":
+		return []option.ClientOption{option.WithCredentialsFile(c.credentialsFile)}, nil
+	}
+
+	return nil, nil
+}
+
+func (c config) newFirestoreClient(ctx context.Context) (*firestore.Client, error) {
+	opts, err := c.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.databaseID != "" && c.databaseID != firestore.DefaultDatabaseID {
+		return firestore.NewClientWithDatabase(ctx, c.projectID, c.databaseID, opts...)
+	}
+
+	return firestore.NewClient(ctx, c.projectID, opts...)
+}
+
+// emulatorCredentials authorizes requests to the emulator as an admin, like the firestore client
+// does for EmulatorHostEnv.
+type emulatorCredentials struct{}
+
+func (emulatorCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer owner"}, nil
+}
+
+func (emulatorCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,134 @@
+package fs
+
+import (
+	"context"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net"
+	"os"
+	"time"
+)
+
+var _ = Describe("fs options unit tests", func() {
+	var env map[string]string
+
+	BeforeEach(func() {
+		env = map[string]string{}
+		for _, key := range []string{model.RunningEnvironment, EmulatorHostEnv, DryRunEnv, DryRunDirEnv} {
+			env[key] = os.Getenv(key)
+			Expect(os.Unsetenv(key)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		for key, value := range env {
+			Expect(os.Setenv(key, value)).To(Succeed())
+		}
+	})
+
+	It("uses the credentials file locally", func() {
+		c := newConfig()
+
+		Expect(c.projectID).To(Equal(FirebaseProjectID))
+		Expect(c.credentialsFile).To(Equal(DefaultCredentialsFile))
+		Expect(c.emulatorHost).To(BeEmpty())
+	})
+
+	It("uses application default credentials in production", func() {
+		Expect(os.Setenv(model.RunningEnvironment, string(model.Production))).To(Succeed())
+
+		opts, err := newConfig().clientOptions()
+
+		Expect(err).To(BeNil())
+		Expect(opts).To(BeEmpty())
+	})
+
+	It("reads the emulator host from the environment", func() {
+		Expect(os.Setenv(EmulatorHostEnv, "localhost:8080")).To(Succeed())
+
+		c := newConfig()
+		opts, err := c.clientOptions()
+
+		Expect(c.emulatorHost).To(Equal("localhost:8080"))
+		Expect(err).To(BeNil())
+		Expect(opts).ToNot(BeEmpty())
+	})
+
+	It("reads the dry-run mode from the environment", func() {
+		Expect(newConfig().dryRun).To(BeFalse())
+
+		Expect(os.Setenv(DryRunEnv, "true")).To(Succeed())
+		Expect(os.Setenv(DryRunDirEnv, "payloads")).To(Succeed())
+
+		c := newConfig()
+		Expect(c.dryRun).To(BeTrue())
+		Expect(c.dryRunDir).To(Equal("payloads"))
+		Expect(newConfig(WithDryRun(false)).dryRun).To(BeFalse())
+	})
+
+	It("ignores the environment in memory", func() {
+		Expect(os.Setenv(DryRunEnv, "true")).To(Succeed())
+		Expect(os.Setenv(DryRunDirEnv, "payloads")).To(Succeed())
+		Expect(os.Setenv(EmulatorHostEnv, "localhost:8080")).To(Succeed())
+
+		c := newMemoryConfig()
+		Expect(c.dryRun).To(BeFalse())
+		Expect(c.dryRunDir).To(BeEmpty())
+		Expect(c.emulatorHost).To(BeEmpty())
+		Expect(c.credentialsFile).To(BeEmpty())
+		Expect(c.writer).To(Equal(MemoryWriter))
+		Expect(NewMemory().dryRun).To(BeFalse())
+		Expect(NewMemory(WithDryRun(true)).dryRun).To(BeTrue())
+	})
+
+	It("caps the lease clock skew at a third of the lease TTL", func() {
+		Expect(newMemoryConfig().newClient(nil).leaseClockSkew).To(Equal(DefaultLeaseClockSkew))
+
+		c := newMemoryConfig(WithLeaseTTL(30*time.Second), WithLeaseClockSkew(time.Minute)).newClient(nil)
+		Expect(c.leaseClockSkew).To(Equal(10 * time.Second))
+	})
+
+	It("applies options in order", func() {
+		c := newConfig(
+			WithProjectID("project"),
+			WithDatabaseID("database"),
+			WithCredentialsFile("file.json"),
+			WithCredentialsJSON([]byte("{}")),
+			WithEmulator("localhost:9090"),
+		)
+
+		Expect(c.projectID).To(Equal("project"))
+		Expect(c.databaseID).To(Equal("database"))
+		Expect(c.credentialsFile).To(BeEmpty())
+		Expect(c.credentialsJSON).To(Equal([]byte("{}")))
+		Expect(c.emulatorHost).To(Equal("localhost:9090"))
+	})
+
+	It("connects to the emulator as an admin without TLS", func() {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).To(BeNil())
+
+		authorization := make(chan []string, 1)
+		srv := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			md, _ := metadata.FromIncomingContext(stream.Context())
+			authorization <- md.Get("authorization")
+			return status.Error(codes.Unimplemented, "emulator")
+		}))
+		go func() { _ = srv.Serve(lis) }()
+		defer srv.Stop()
+
+		c, err := New(context.Background(), WithProjectID("project"), WithEmulator(lis.Addr().String()))
+		Expect(err).To(BeNil())
+
+		_, err = c.GetBangumiToken(context.Background())
+		Expect(status.Code(err)).To(Equal(codes.Unimplemented))
+		Expect(authorization).To(Receive(Equal([]string{"Bearer owner"})))
+
+		Expect(c.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,187 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/model"
+	"time"
+)
+
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// Batch groups published documents so that Publish writes them atomically with a shared server timestamp.
+type Batch struct {
+	docs          []pendingDocument
+	preconditions []precondition
+}
+
+type precondition struct {
+	path            string
+	lastUpdatedDate time.Time
+}
+
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+func (b *Batch) UpdateMonoDocument(monoType model.MonoType, data model.FirestoreMonoDocument) *Batch {
+	return b.add(monoDocument(monoType, data))
+}
+
+func (b *Batch) UpdateSeasonIndex(items []model.FirestoreSeasonIndexItem) *Batch {
+	return b.add(seasonIndexDocument(items))
+}
+
+func (b *Batch) UpdateTrendingSubjects(subjectTypeID string, subjects []model.FirestoreSubject) *Batch {
+	return b.add(trendingSubjectsDocument(subjectTypeID, subjects))
+}
+
+func (b *Batch) UpdateSeasonalSubjects(id string, subjects []model.FirestoreSeasonSubject) *Batch {
+	return b.add(seasonalSubjectsDocument(id, subjects))
+}
+
+func (b *Batch) UpdateDiscoverySubjects(id model.SubjectTypeID, data []model.FirestoreDiscoverySubject) *Batch {
+	return b.add(discoverySubjectsDocument(id, data))
+}
+
+func (b *Batch) UpdateRelatedSubjects(subjectID int, subjects []model.FirestoreSubject) *Batch {
+	return b.add(relatedSubjectsDocument(subjectID, subjects))
+}
+
+// RequireUnchanged fails the publish with ErrPreconditionFailed unless the document at path was last
+// updated at lastUpdatedDate, as returned by its getter. A zero lastUpdatedDate requires the document
+// not to exist.
+func (b *Batch) RequireUnchanged(path string, lastUpdatedDate time.Time) *Batch {
+	b.preconditions = append(b.preconditions, precondition{path: path, lastUpdatedDate: lastUpdatedDate})
+	return b
+}
+
+func (b *Batch) add(doc pendingDocument) *Batch {
+	b.docs = append(b.docs, doc)
+	return b
+}
+
+// Publish writes every document of the batch in one transaction after checking its preconditions.
+// Either all documents are published, archived and sharded as by their Update* methods, or none is.
+// Documents with unchanged content are skipped. It returns the diff of every document of the batch.
+// In dry-run mode the documents are reported instead of written.
+func (c *Client) Publish(ctx context.Context, b *Batch) ([]Diff, error) {
+	seen := make(map[string]bool, len(b.docs))
+	for _, doc := range b.docs {
+		if seen[doc.path] {
+			return nil, fmt.Errorf("fs: %s is published twice in the batch", doc.path)
+		}
+		seen[doc.path] = true
+	}
+
+	// plans are only reported once the transaction succeeded, so that retries do not report them again.
+	var plans []*publishPlan
+	err := c.store.runTransaction(ctx, func(ctx context.Context, tx ops) error {
+		plans = make([]*publishPlan, 0, len(b.docs))
+
+		for _, p := range b.preconditions {
+			if err := p.check(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		for _, doc := range b.docs {
+			plan, err := c.prepare(ctx, tx, doc.clone())
+			if err != nil {
+				return err
+			}
+			plans = append(plans, plan)
+		}
+
+		if c.dryRun {
+			return nil
+		}
+
+		for _, plan := range plans {
+			if err := c.apply(ctx, tx, plan); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]Diff, 0, len(plans))
+	for _, plan := range plans {
+		if c.dryRun {
+			if err := c.report(plan); err != nil {
+				return nil, err
+			}
+		}
+		diffs = append(diffs, plan.diff)
+	}
+
+	return diffs, nil
+}
+
+// PublishSeason publishes the subjects of the season id together with the season index under
+// SeasonLeaseName, so that season publishers do not overlap. merge returns the new index from the
+// current one, which is read under the lease and required to be unchanged when the batch is written,
+// e.g. by calling season.MergeIndex. It returns ErrLeaseHeld if another job holds the lease.
+func (c *Client) PublishSeason(ctx context.Context, id string, subjects []model.FirestoreSeasonSubject, merge func(existing []model.FirestoreSeasonIndexItem) []model.FirestoreSeasonIndexItem) ([]Diff, error) {
+	var diffs []Diff
+	err := c.WithLease(ctx, SeasonLeaseName, func(ctx context.Context) error {
+		var existing []model.FirestoreSeasonIndexItem
+		var lastUpdatedDate time.Time
+
+		index, err := c.GetSeasonIndex(ctx)
+		switch {
+		case err == nil:
+			existing, lastUpdatedDate = index.Data, index.LastUpdatedDate
+		case !errors.Is(err, ErrDocumentDoesNotExist):
+			return err
+		}
+
+		batch := NewBatch().
+			RequireUnchanged(DocumentPath(SeasonCollectionKey, SeasonCollectionIndexDocKey), lastUpdatedDate).
+			UpdateSeasonalSubjects(id, subjects).
+			UpdateSeasonIndex(merge(existing))
+
+		diffs, err = c.Publish(ctx, batch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return diffs, nil
+}
+
+func (p precondition) check(ctx context.Context, o ops) error {
+	doc, err := o.get(ctx, p.path)
+	if errors.Is(err, ErrDocumentDoesNotExist) {
+		if p.lastUpdatedDate.IsZero() {
+			return nil
+		}
+		return fmt.Errorf("%w: %s does not exist", ErrPreconditionFailed, p.path)
+	}
+	if err != nil {
+		return err
+	}
+
+	lastUpdatedDate, _ := doc.data[FirebaseLastUpdatedTimestampKey].(time.Time)
+	if !lastUpdatedDate.Equal(p.lastUpdatedDate) {
+		return fmt.Errorf("%w: %s was updated at %s", ErrPreconditionFailed, p.path, lastUpdatedDate)
+	}
+
+	return nil
+}
+
+// clone copies the top level fields, so that a retried transaction starts from the original data.
+func (d pendingDocument) clone() pendingDocument {
+	data := make(map[string]interface{}, len(d.data))
+	for k, v := range d.data {
+		data[k] = v
+	}
+	d.data = data
+	return d
+}
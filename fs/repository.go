@@ -0,0 +1,53 @@
+package fs
+
+import (
+	"context"
+	"github.com/bangumilite/bangumilite-component/bangumi"
+	"github.com/bangumilite/bangumilite-component/history"
+	"github.com/bangumilite/bangumilite-component/mailer"
+	"github.com/bangumilite/bangumilite-component/model"
+)
+
+// Repository covers every document read and write of the fs package. Client is backed by Firestore,
+// Memory keeps documents in memory for tests.
+type Repository interface {
+	history.Store
+
+	GetBangumiToken(ctx context.Context) (*model.FirestoreBangumiToken, error)
+	GetMailgunConfig(ctx context.Context) (*mailer.MailgunConfig, error)
+	GetSeasonIndex(ctx context.Context) (*model.FirestoreSeasonIndexDocument, error)
+	GetMonoDocument(ctx context.Context, monoType model.MonoType) (*model.FirestoreDocument[model.FirestoreMonoDocument], error)
+	GetTrendingSubjects(ctx context.Context, subjectTypeID string) (*model.FirestoreDocument[[]model.FirestoreSubject], error)
+	GetSeasonalSubjects(ctx context.Context, id string) (*model.FirestoreDocument[[]model.FirestoreSeasonSubject], error)
+	GetDiscoverySubjects(ctx context.Context, id model.SubjectTypeID) (*model.FirestoreDocument[[]model.FirestoreDiscoverySubject], error)
+	GetRelatedSubjects(ctx context.Context, subjectID int) (*model.FirestoreDocument[[]model.FirestoreSubject], error)
+
+	UpdateMonoDocument(ctx context.Context, monoType model.MonoType, data model.FirestoreMonoDocument) (Diff, error)
+	UpdateSeasonIndex(ctx context.Context, items []model.FirestoreSeasonIndexItem) (Diff, error)
+	UpdateBangumiToken(ctx context.Context, accessToken string, refreshToken string) error
+	UpdateTrendingSubjects(ctx context.Context, subjectTypeID string, subjects []model.FirestoreSubject) (Diff, error)
+	UpdateSeasonalSubjects(ctx context.Context, id string, subjects []model.FirestoreSeasonSubject) (Diff, error)
+	UpdateDiscoverySubjects(ctx context.Context, id model.SubjectTypeID, data []model.FirestoreDiscoverySubject) (Diff, error)
+	UpdateRelatedSubjects(ctx context.Context, subjectID int, subjects []model.FirestoreSubject) (Diff, error)
+
+	ListenBangumiToken(ctx context.Context, fn func(token *model.FirestoreBangumiToken) error) error
+	ListenMailgunConfig(ctx context.Context, fn func(config *mailer.MailgunConfig) error) error
+	WatchBangumiToken(ctx context.Context) <-chan *model.FirestoreBangumiToken
+	WatchMailgunConfig(ctx context.Context) <-chan *mailer.MailgunConfig
+
+	Publish(ctx context.Context, b *Batch) ([]Diff, error)
+	PublishSeason(ctx context.Context, id string, subjects []model.FirestoreSeasonSubject, merge func(existing []model.FirestoreSeasonIndexItem) []model.FirestoreSeasonIndexItem) ([]Diff, error)
+
+	AcquireLease(ctx context.Context, name string) (*Lease, error)
+	RenewLease(ctx context.Context, lease *Lease) error
+	ReleaseLease(ctx context.Context, lease *Lease) error
+	WithLease(ctx context.Context, name string, fn func(ctx context.Context) error) error
+	RefreshBangumiToken(ctx context.Context, refresher bangumi.TokenRefresher) (*model.FirestoreBangumiToken, error)
+
+	ListVersions(ctx context.Context, path string) ([]Version, error)
+	Rollback(ctx context.Context, path string, version string) error
+
+	Close() error
+}
+
+var _ Repository = (*Client)(nil)
@@ -0,0 +1,241 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+// describeRepository declares the specs every Repository implementation has to pass.
+// newRepository is called before each spec and must return an empty repository.
+func describeRepository(newRepository func() Repository) {
+	var (
+		ctx  context.Context
+		repo Repository
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = newRepository()
+	})
+
+	AfterEach(func() {
+		if repo != nil {
+			Expect(repo.Close()).To(Succeed())
+			repo = nil
+		}
+	})
+
+	It("returns ErrDocumentDoesNotExist for missing documents", func() {
+		_, err := repo.GetMailgunConfig(ctx)
+
+		Expect(errors.Is(err, ErrDocumentDoesNotExist)).To(BeTrue())
+	})
+
+	It("reads back the season index", func() {
+		items := []model.FirestoreSeasonIndexItem{{ID: "202504", Image: "a", BlurHash: "hash"}}
+
+		Expect(repo.UpdateSeasonIndex(ctx, items)).Error().To(Succeed())
+
+		got, err := repo.GetSeasonIndex(ctx)
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(Equal(items))
+	})
+
+	It("keeps other token fields when updating the token", func() {
+		Expect(repo.UpdateBangumiToken(ctx, "access", "refresh")).To(Succeed())
+		Expect(repo.UpdateBangumiToken(ctx, "access2", "refresh2")).To(Succeed())
+
+		got, err := repo.GetBangumiToken(ctx)
+		Expect(err).To(BeNil())
+		Expect(got.AccessToken).To(Equal("access2"))
+		Expect(got.RefreshToken).To(Equal("refresh2"))
+	})
+
+	It("grants a lease to one owner at a time", func() {
+		lease, err := repo.AcquireLease(ctx, SeasonLeaseName)
+		Expect(err).To(BeNil())
+
+		_, err = repo.AcquireLease(ctx, SeasonLeaseName)
+		Expect(errors.Is(err, ErrLeaseHeld)).To(BeTrue())
+
+		Expect(repo.RenewLease(ctx, lease)).To(Succeed())
+		Expect(repo.ReleaseLease(ctx, lease)).To(Succeed())
+		Expect(errors.Is(repo.RenewLease(ctx, lease), ErrLeaseLost)).To(BeTrue())
+
+		_, err = repo.AcquireLease(ctx, SeasonLeaseName)
+		Expect(err).To(BeNil())
+	})
+
+	It("delivers token updates to watchers", func() {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		tokens := repo.WatchBangumiToken(watchCtx)
+
+		Expect(repo.UpdateBangumiToken(ctx, "access", "refresh")).To(Succeed())
+		Eventually(tokens, 5*time.Second).Should(Receive(HaveField("AccessToken", "access")))
+
+		Expect(repo.UpdateBangumiToken(ctx, "access2", "refresh2")).To(Succeed())
+		Eventually(tokens, 5*time.Second).Should(Receive(HaveField("AccessToken", "access2")))
+
+		cancel()
+		Eventually(tokens, 5*time.Second).Should(BeClosed())
+	})
+
+	It("replaces the related subjects of a subject", func() {
+		Expect(repo.UpdateRelatedSubjects(ctx, 1, []model.FirestoreSubject{{ID: 2}, {ID: 3}})).Error().To(Succeed())
+		Expect(repo.UpdateRelatedSubjects(ctx, 1, []model.FirestoreSubject{{ID: 4}})).Error().To(Succeed())
+		Expect(repo.UpdateRelatedSubjects(ctx, 2, []model.FirestoreSubject{{ID: 1}})).Error().To(Succeed())
+
+		got, err := repo.GetRelatedSubjects(ctx, 1)
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(Equal([]model.FirestoreSubject{{ID: 4}}))
+
+		_, err = repo.GetRelatedSubjects(ctx, 3)
+		Expect(errors.Is(err, ErrDocumentDoesNotExist)).To(BeTrue())
+	})
+
+	It("reads back every published document", func() {
+		before := time.Now().Add(-time.Minute)
+		image := "image"
+		mono := model.FirestoreMonoDocument{Trending: []model.FirestoreMono{{ID: 1, Name: "name", Image: &image}}}
+		subjects := []model.FirestoreSubject{{ID: 1, Name: "name", Score: 7.5}}
+		seasonal := []model.FirestoreSeasonSubject{{ID: 1, Actors: []model.BangumiPerson{}, Staff: []string{"staff"}, Continuing: true}}
+		discovery := []model.FirestoreDiscoverySubject{{Title: "title", Data: subjects}}
+
+		Expect(repo.UpdateMonoDocument(ctx, model.MonoType("character"), mono)).Error().To(Succeed())
+		Expect(repo.UpdateTrendingSubjects(ctx, "2", subjects)).Error().To(Succeed())
+		Expect(repo.UpdateSeasonalSubjects(ctx, "202504", seasonal)).Error().To(Succeed())
+		Expect(repo.UpdateDiscoverySubjects(ctx, model.AnimeID, discovery)).Error().To(Succeed())
+		Expect(repo.UpdateRelatedSubjects(ctx, 1, subjects)).Error().To(Succeed())
+
+		gotMono, err := repo.GetMonoDocument(ctx, model.MonoType("character"))
+		Expect(err).To(BeNil())
+		Expect(gotMono.Data).To(Equal(mono))
+		Expect(gotMono.LastUpdatedDate.After(before)).To(BeTrue())
+
+		gotTrending, err := repo.GetTrendingSubjects(ctx, "2")
+		Expect(err).To(BeNil())
+		Expect(gotTrending.Data).To(Equal(subjects))
+		Expect(gotTrending.LastUpdatedDate.After(before)).To(BeTrue())
+
+		gotSeasonal, err := repo.GetSeasonalSubjects(ctx, "202504")
+		Expect(err).To(BeNil())
+		Expect(gotSeasonal.Data).To(Equal(seasonal))
+
+		gotDiscovery, err := repo.GetDiscoverySubjects(ctx, model.AnimeID)
+		Expect(err).To(BeNil())
+		Expect(gotDiscovery.Data).To(Equal(discovery))
+
+		gotRelated, err := repo.GetRelatedSubjects(ctx, 1)
+		Expect(err).To(BeNil())
+		Expect(gotRelated.Data).To(Equal(subjects))
+	})
+
+	It("returns ErrDocumentDoesNotExist for unpublished documents", func() {
+		_, err := repo.GetTrendingSubjects(ctx, "2")
+
+		Expect(errors.Is(err, ErrDocumentDoesNotExist)).To(BeTrue())
+	})
+
+	It("archives and rolls back published documents", func() {
+		path := DocumentPath(TrendingCollectionKey, "2")
+
+		Expect(repo.UpdateTrendingSubjects(ctx, "2", []model.FirestoreSubject{{ID: 1}})).Error().To(Succeed())
+		Expect(repo.UpdateTrendingSubjects(ctx, "2", []model.FirestoreSubject{{ID: 2}})).Error().To(Succeed())
+
+		versions, err := repo.ListVersions(ctx, path)
+		Expect(err).To(BeNil())
+		Expect(versions).To(HaveLen(1))
+
+		Expect(repo.Rollback(ctx, path, versions[0].ID)).To(Succeed())
+
+		got, err := repo.GetTrendingSubjects(ctx, "2")
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(Equal([]model.FirestoreSubject{{ID: 1}}))
+
+		versions, err = repo.ListVersions(ctx, path)
+		Expect(err).To(BeNil())
+		Expect(versions).To(HaveLen(2))
+	})
+
+	It("returns ErrVersionDoesNotExist for unknown versions", func() {
+		err := repo.Rollback(ctx, DocumentPath(TrendingCollectionKey, "2"), "unknown")
+
+		Expect(errors.Is(err, ErrVersionDoesNotExist)).To(BeTrue())
+	})
+
+	It("publishes batches atomically", func() {
+		items := []model.FirestoreSeasonIndexItem{{ID: "202504"}}
+		Expect(repo.UpdateSeasonIndex(ctx, items)).Error().To(Succeed())
+
+		index, err := repo.GetSeasonIndex(ctx)
+		Expect(err).To(BeNil())
+
+		subjects := []model.FirestoreSeasonSubject{{ID: 1}}
+		batch := NewBatch().
+			RequireUnchanged(DocumentPath(SeasonCollectionKey, SeasonCollectionIndexDocKey), index.LastUpdatedDate).
+			UpdateSeasonalSubjects("202507", subjects).
+			UpdateSeasonIndex(append(items, model.FirestoreSeasonIndexItem{ID: "202507"}))
+
+		diffs, err := repo.Publish(ctx, batch)
+		Expect(err).To(BeNil())
+		Expect(diffs).To(HaveLen(2))
+		Expect(diffs[0].Created).To(BeTrue())
+		Expect(diffs[1].Fields).To(Equal([]string{"data"}))
+
+		season, err := repo.GetSeasonalSubjects(ctx, "202507")
+		Expect(err).To(BeNil())
+		index, err = repo.GetSeasonIndex(ctx)
+		Expect(err).To(BeNil())
+		Expect(index.Data).To(HaveLen(2))
+		Expect(season.LastUpdatedDate).To(Equal(index.LastUpdatedDate))
+
+		versions, err := repo.ListVersions(ctx, DocumentPath(SeasonCollectionKey, SeasonCollectionIndexDocKey))
+		Expect(err).To(BeNil())
+		Expect(versions).To(HaveLen(1))
+	})
+
+	It("fails batches whose preconditions do not hold", func() {
+		Expect(repo.UpdateSeasonIndex(ctx, nil)).Error().To(Succeed())
+
+		batch := NewBatch().
+			RequireUnchanged(DocumentPath(SeasonCollectionKey, SeasonCollectionIndexDocKey), time.Time{}).
+			UpdateSeasonalSubjects("202507", nil)
+
+		_, err := repo.Publish(ctx, batch)
+		Expect(errors.Is(err, ErrPreconditionFailed)).To(BeTrue())
+
+		_, err = repo.GetSeasonalSubjects(ctx, "202507")
+		Expect(errors.Is(err, ErrDocumentDoesNotExist)).To(BeTrue())
+	})
+
+	It("queries subject snapshots", func() {
+		now := time.Now().UTC().Truncate(time.Second)
+		day := 24 * time.Hour
+
+		Expect(repo.SaveSubjectSnapshots(ctx, []model.FirestoreSubjectSnapshot{
+			{SubjectID: 1, Timestamp: now.Add(-2 * day), Score: 7},
+			{SubjectID: 1, Timestamp: now, Score: 8},
+			{SubjectID: 2, Timestamp: now, Score: 6},
+		})).To(Succeed())
+
+		got, err := repo.GetSubjectSnapshots(ctx, now.Add(-day))
+		Expect(err).To(BeNil())
+		Expect(got).To(HaveLen(2))
+		for _, snapshot := range got {
+			Expect(snapshot.Timestamp.Equal(now)).To(BeTrue())
+		}
+
+		got, err = repo.GetSubjectSnapshotsByID(ctx, 1, now.Add(-3*day))
+		Expect(err).To(BeNil())
+		Expect(got).To(HaveLen(2))
+		for _, snapshot := range got {
+			Expect(snapshot.SubjectID).To(Equal(1))
+		}
+	})
+}
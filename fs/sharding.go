@@ -0,0 +1,390 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxDocumentSize is the Firestore document size limit.
+	MaxDocumentSize = 1 << 20
+
+	// DefaultShardThreshold leaves headroom below MaxDocumentSize for estimation error.
+	DefaultShardThreshold = 900 << 10
+
+	ShardCollectionKey = "shards"
+
+	// FirebaseShardsKey holds the shard ids of a sharded document, its "data" field is split across
+	// these documents of the shards subcollection in order.
+	FirebaseShardsKey = "shards"
+
+	firebaseDataKey = "data"
+)
+
+// readAttempts bounds how often a read of a sharded document starts over because a write replaced
+// its shards while it was reading them.
+const readAttempts = 3
+
+var (
+	ErrDocumentTooLarge = errors.New("document too large")
+
+	errShardReplaced = errors.New("shard replaced")
+)
+
+// EstimateSize estimates the stored size in bytes of the document at path with the given data,
+// following the Firestore storage size rules. Compare it with MaxDocumentSize to log headroom.
+func EstimateSize(path string, data interface{}) (int, error) {
+	encoded, err := encode(data)
+	if err != nil {
+		return 0, err
+	}
+
+	doc, ok := encoded.(map[string]interface{})
+	if !ok {
+		return 0, fmt.Errorf("fs: document data must be a struct or map, got %T", data)
+	}
+
+	return documentSize(path, doc), nil
+}
+
+func documentSize(path string, doc map[string]interface{}) int {
+	return nameSize(path) + mapSize(doc) + 32
+}
+
+func nameSize(path string) int {
+	size := 16
+	for _, segment := range strings.Split(path, "/") {
+		size += len(segment) + 1
+	}
+	return size
+}
+
+func mapSize(m map[string]interface{}) int {
+	size := 0
+	for k, v := range m {
+		size += len(k) + 1 + valueSize(v)
+	}
+	return size
+}
+
+func valueSize(v interface{}) int {
+	switch x := v.(type) {
+	case nil, bool:
+		return 1
+	case int64, float64, time.Time:
+		return 8
+	case string:
+		return len(x) + 1
+	case []byte:
+		return len(x)
+	case []interface{}:
+		size := 0
+		for _, e := range x {
+			size += valueSize(e)
+		}
+		return size
+	case map[string]interface{}:
+		return mapSize(x)
+	}
+
+	if v == firestore.ServerTimestamp {
+		return 8
+	}
+	return 0
+}
+
+// write sets the document at path, splitting its "data" array across shard documents when the
+// encoded document exceeds the shard threshold. existing lists the shard ids of the document before
+// the write, shards that are no longer used are deleted. write does not read, so that it can be used
+// after the reads of a transaction.
+//
+// Shard ids are scoped to a generation named after the content, e.g. "<hash>-0", so that a write never
+// modifies the shards of the manifest it replaces. Outside a transaction the new shards are written
+// first, then the manifest is switched to them and only then are the old shards deleted: a reader sees
+// either generation in full, or finds a shard of its manifest deleted and starts over, see read.
+func (c *Client) write(ctx context.Context, o ops, path string, data map[string]interface{}, merge bool, existing []string) error {
+	encoded, err := encode(data)
+	if err != nil {
+		return err
+	}
+
+	items, ok := encoded.(map[string]interface{})[firebaseDataKey].([]interface{})
+	if !ok || c.shardThreshold == 0 || documentSize(path, encoded.(map[string]interface{})) <= c.shardThreshold {
+		if merge && len(existing) > 0 {
+			data[FirebaseShardsKey] = firestore.Delete
+		}
+
+		if err := o.set(ctx, path, data, merge); err != nil {
+			return err
+		}
+
+		return deleteShards(ctx, o, path, existing, nil)
+	}
+
+	gen, err := generation(data)
+	if err != nil {
+		return err
+	}
+
+	counts, err := c.split(path, gen, items)
+	if err != nil {
+		return err
+	}
+
+	// The shards hold slices of the caller's "data" value rather than its encoded items, so that the
+	// firestore backend marshals them itself.
+	all := reflect.ValueOf(data[firebaseDataKey])
+	start := 0
+
+	ids := make([]string, len(counts))
+	for i, n := range counts {
+		ids[i] = shardID(gen, i)
+
+		chunk := all.Slice(start, start+n).Interface()
+		if err := o.set(ctx, shardPath(path, ids[i]), map[string]interface{}{firebaseDataKey: chunk}, false); err != nil {
+			return err
+		}
+		start += n
+	}
+
+	manifest := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		manifest[k] = v
+	}
+	delete(manifest, firebaseDataKey)
+	if merge {
+		manifest[firebaseDataKey] = firestore.Delete
+	}
+	manifest[FirebaseShardsKey] = ids
+
+	if err := o.set(ctx, path, manifest, merge); err != nil {
+		return err
+	}
+
+	return deleteShards(ctx, o, path, existing, ids)
+}
+
+// split packs the encoded items into chunks whose shard documents stay within the shard threshold and
+// returns the number of items of every chunk.
+func (c *Client) split(path string, gen string, items []interface{}) ([]int, error) {
+	overhead := documentSize(shardPath(path, shardID(gen, len(items))), map[string]interface{}{firebaseDataKey: []interface{}{}})
+
+	var counts []int
+	n := 0
+	size := overhead
+
+	for i, item := range items {
+		itemSize := valueSize(item)
+		if overhead+itemSize > c.shardThreshold {
+			return nil, fmt.Errorf("%w: item %d of %s is %d bytes", ErrDocumentTooLarge, i, path, itemSize)
+		}
+
+		if size+itemSize > c.shardThreshold {
+			counts = append(counts, n)
+			n, size = 0, overhead
+		}
+
+		n++
+		size += itemSize
+	}
+
+	return append(counts, n), nil
+}
+
+// read returns the document at path with the "data" array of a sharded document reassembled. It starts
+// over when a concurrent write replaced the shards it was reading.
+func (c *Client) read(ctx context.Context, o ops, path string) (map[string]interface{}, error) {
+	var err error
+	for attempt := 0; attempt < readAttempts; attempt++ {
+		var doc document
+		if doc, err = o.get(ctx, path); err != nil {
+			return nil, err
+		}
+
+		var data map[string]interface{}
+		data, err = assemble(ctx, o, path, doc.data)
+		if !errors.Is(err, errShardReplaced) {
+			return data, err
+		}
+	}
+
+	return nil, err
+}
+
+// readTo decodes the document at path into the struct v points to, decoding the "data" field of a
+// sharded document from its shards. Documents are decoded by their backend, see document.dataTo. Like
+// read it starts over when a concurrent write replaced the shards it was reading.
+func readTo(ctx context.Context, o ops, path string, v interface{}) error {
+	var err error
+	for attempt := 0; attempt < readAttempts; attempt++ {
+		if err = readShardsTo(ctx, o, path, v); !errors.Is(err, errShardReplaced) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func readShardsTo(ctx context.Context, o ops, path string, v interface{}) error {
+	doc, err := o.get(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	if err := doc.dataTo(v); err != nil {
+		return err
+	}
+
+	ids, ok := manifestShards(doc.data)
+	if !ok {
+		return nil
+	}
+
+	dst := reflect.ValueOf(v).Elem()
+	if dst.Kind() != reflect.Struct {
+		return fmt.Errorf("fs: cannot decode sharded document %s into %s", path, dst.Type())
+	}
+	f, ok := matchField(structFields(dst.Type()), firebaseDataKey)
+	if !ok {
+		return nil
+	}
+	field := fieldByIndexAlloc(dst, f.index)
+	if field.Kind() != reflect.Slice {
+		return fmt.Errorf("fs: cannot decode the shards of %s into %s", path, field.Type())
+	}
+
+	chunkType := reflect.StructOf([]reflect.StructField{{
+		Name: "Data",
+		Type: field.Type(),
+		Tag:  reflect.StructTag(`firestore:"` + firebaseDataKey + `"`),
+	}})
+
+	items := reflect.MakeSlice(field.Type(), 0, 0)
+	for _, id := range ids {
+		shard, err := getShard(ctx, o, path, id)
+		if err != nil {
+			return err
+		}
+
+		chunk := reflect.New(chunkType)
+		if err := shard.dataTo(chunk.Interface()); err != nil {
+			return fmt.Errorf("shard %s of %s: %w", id, path, err)
+		}
+		items = reflect.AppendSlice(items, chunk.Elem().Field(0))
+	}
+	field.Set(items)
+
+	return nil
+}
+
+// assemble reads the shards of a document read as data and reassembles its "data" array.
+func assemble(ctx context.Context, o ops, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	ids, ok := manifestShards(data)
+	if !ok {
+		return data, nil
+	}
+
+	items := make([]interface{}, 0)
+	for _, id := range ids {
+		shard, err := getShard(ctx, o, path, id)
+		if err != nil {
+			return nil, err
+		}
+
+		chunk, _ := shard.data[firebaseDataKey].([]interface{})
+		items = append(items, chunk...)
+	}
+
+	delete(data, FirebaseShardsKey)
+	data[firebaseDataKey] = items
+
+	return data, nil
+}
+
+// getShard reads a shard of the manifest at path. A missing shard was deleted by a write that replaced
+// the manifest after it was read, so the error wraps errShardReplaced as well as ErrDocumentDoesNotExist.
+func getShard(ctx context.Context, o ops, path string, id string) (document, error) {
+	shard, err := o.get(ctx, shardPath(path, id))
+	if errors.Is(err, ErrDocumentDoesNotExist) {
+		return document{}, fmt.Errorf("%w: %w: shard %s of %s", ErrDocumentDoesNotExist, errShardReplaced, id, path)
+	}
+
+	return shard, err
+}
+
+// manifestShards returns the shard ids of a sharded document.
+func manifestShards(data map[string]interface{}) ([]string, bool) {
+	values, ok := data[FirebaseShardsKey].([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	ids := make([]string, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, true
+}
+
+func shardIDs(ctx context.Context, o ops, path string) ([]string, error) {
+	docs, err := o.query(ctx, path+"/"+ShardCollectionKey, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.id)
+	}
+
+	return ids, nil
+}
+
+// deleteShards deletes the shards with the given ids except the ones to keep.
+func deleteShards(ctx context.Context, o ops, path string, ids []string, keep []string) error {
+	for _, id := range ids {
+		if slices.Contains(keep, id) {
+			continue
+		}
+
+		if err := o.delete(ctx, shardPath(path, id)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generation names the shards of a write after the content hash of data.
+func generation(data map[string]interface{}) (string, error) {
+	hash, ok := data[FirebaseContentHashKey].(string)
+	if !ok {
+		next, err := content(data)
+		if err != nil {
+			return "", err
+		}
+		if hash, err = contentHash(next); err != nil {
+			return "", err
+		}
+	}
+
+	return hash[:min(len(hash), 16)], nil
+}
+
+func shardID(gen string, i int) string {
+	return gen + "-" + strconv.Itoa(i)
+}
+
+func shardPath(path string, id string) string {
+	return DocumentPath(path+"/"+ShardCollectionKey, id)
+}
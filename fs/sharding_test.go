@@ -0,0 +1,220 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strings"
+)
+
+// hookStore runs hooks after every write and before every read of a shard.
+type hookStore struct {
+	store
+
+	afterWrite     func()
+	beforeShardGet func()
+}
+
+func (s *hookStore) get(ctx context.Context, path string) (document, error) {
+	if s.beforeShardGet != nil && strings.Contains(path, "/"+ShardCollectionKey+"/") {
+		s.beforeShardGet()
+	}
+
+	return s.store.get(ctx, path)
+}
+
+func (s *hookStore) set(ctx context.Context, path string, data interface{}, merge bool) error {
+	if err := s.store.set(ctx, path, data, merge); err != nil {
+		return err
+	}
+
+	if s.afterWrite != nil {
+		s.afterWrite()
+	}
+	return nil
+}
+
+func (s *hookStore) delete(ctx context.Context, path string) error {
+	if err := s.store.delete(ctx, path); err != nil {
+		return err
+	}
+
+	if s.afterWrite != nil {
+		s.afterWrite()
+	}
+	return nil
+}
+
+var _ = Describe("fs sharding unit tests", func() {
+	var (
+		ctx context.Context
+		m   *Memory
+	)
+
+	subjects := func(n int) []model.FirestoreSeasonSubject {
+		s := make([]model.FirestoreSeasonSubject, n)
+		for i := range s {
+			s[i] = model.FirestoreSeasonSubject{ID: i + 1, Summary: strings.Repeat("a", 100)}
+		}
+		return s
+	}
+
+	shards := func(path string) []string {
+		var paths []string
+		for _, p := range m.Paths() {
+			if strings.HasPrefix(p, path+"/"+ShardCollectionKey+"/") {
+				paths = append(paths, p)
+			}
+		}
+		return paths
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		m = NewMemory(WithShardThreshold(1000), WithHistoryLimit(1))
+	})
+
+	Describe("EstimateSize", func() {
+		It("follows the storage size rules", func() {
+			size, err := EstimateSize("a/b", map[string]interface{}{"x": "ab"})
+
+			Expect(err).To(BeNil())
+			Expect(size).To(Equal(20 + 5 + 32))
+		})
+
+		It("sizes models by their firestore fields", func() {
+			size, err := EstimateSize("a/b", model.FirestoreSeasonIndexItem{ID: "1", Image: ""})
+
+			Expect(err).To(BeNil())
+			Expect(size).To(Equal(20 + (3 + 2) + (6 + 1) + 32))
+		})
+
+		It("rejects non document values", func() {
+			_, err := EstimateSize("a/b", []int{1})
+
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	It("splits oversized documents into shards and reassembles them", func() {
+		want := subjects(30)
+
+		Expect(m.UpdateSeasonalSubjects(ctx, "202504", want)).Error().To(Succeed())
+
+		doc, ok := m.Document("season/202504")
+		Expect(ok).To(BeTrue())
+		Expect(doc).ToNot(HaveKey("data"))
+		Expect(doc["total"]).To(Equal(int64(30)))
+		ids, ok := manifestShards(doc)
+		Expect(ok).To(BeTrue())
+		Expect(len(ids)).To(BeNumerically(">", 1))
+
+		for _, id := range ids {
+			shard, ok := m.Document(shardPath("season/202504", id))
+			Expect(ok).To(BeTrue())
+			size := documentSize(shardPath("season/202504", id), shard)
+			Expect(size).To(BeNumerically("<=", 1000))
+		}
+
+		got, err := m.GetSeasonalSubjects(ctx, "202504")
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(Equal(want))
+	})
+
+	It("removes shards when the document fits again", func() {
+		Expect(m.UpdateSeasonalSubjects(ctx, "202504", subjects(30))).Error().To(Succeed())
+		Expect(m.UpdateSeasonalSubjects(ctx, "202504", subjects(2))).Error().To(Succeed())
+
+		doc, ok := m.Document("season/202504")
+		Expect(ok).To(BeTrue())
+		Expect(doc).ToNot(HaveKey(FirebaseShardsKey))
+		Expect(shards("season/202504")).To(BeEmpty())
+
+		got, err := m.GetSeasonalSubjects(ctx, "202504")
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(HaveLen(2))
+	})
+
+	It("archives and rolls back sharded documents", func() {
+		path := DocumentPath(SeasonCollectionKey, "202504")
+
+		Expect(m.UpdateSeasonalSubjects(ctx, "202504", subjects(30))).Error().To(Succeed())
+		Expect(m.UpdateSeasonalSubjects(ctx, "202504", subjects(2))).Error().To(Succeed())
+
+		versions, err := m.ListVersions(ctx, path)
+		Expect(err).To(BeNil())
+		Expect(versions).To(HaveLen(1))
+		Expect(shards(versionPath(path, versions[0].ID))).ToNot(BeEmpty())
+
+		Expect(m.Rollback(ctx, path, versions[0].ID)).To(Succeed())
+
+		got, err := m.GetSeasonalSubjects(ctx, "202504")
+		Expect(err).To(BeNil())
+		Expect(got.Data).To(Equal(subjects(30)))
+	})
+
+	Describe("reading while a publish is in progress", func() {
+		var (
+			hooked *hookStore
+			c      *Client
+			old    []model.FirestoreSeasonSubject
+			next   []model.FirestoreSeasonSubject
+		)
+
+		BeforeEach(func() {
+			old, next = subjects(30), subjects(30)
+			for i := range next {
+				next[i].Summary = strings.Repeat("b", 100)
+			}
+
+			Expect(m.UpdateSeasonalSubjects(ctx, "202504", old)).Error().To(Succeed())
+
+			hooked = &hookStore{store: m.mem}
+			c = newMemoryConfig(WithShardThreshold(1000), WithHistoryLimit(1)).newClient(hooked)
+		})
+
+		It("writes a generation in one transaction", func() {
+			hooked.afterWrite = func() {
+				Fail("wrote outside of a transaction")
+			}
+			writes := len(m.Writes())
+
+			Expect(c.UpdateSeasonalSubjects(ctx, "202504", next)).Error().To(Succeed())
+
+			got, err := m.GetSeasonalSubjects(ctx, "202504")
+			Expect(err).To(BeNil())
+			Expect(got.Data).To(Equal(next))
+
+			published := m.Writes()[writes:]
+			Expect(len(published)).To(BeNumerically(">", 2))
+			for _, w := range published {
+				Expect(w.Time).To(Equal(published[0].Time))
+			}
+		})
+
+		It("starts over when the shards it reads are replaced", func() {
+			published := false
+			hooked.beforeShardGet = func() {
+				if !published {
+					published = true
+					Expect(m.UpdateSeasonalSubjects(ctx, "202504", next)).Error().To(Succeed())
+				}
+			}
+
+			got, err := c.GetSeasonalSubjects(ctx, "202504")
+
+			Expect(err).To(BeNil())
+			Expect(got.Data).To(Equal(next))
+		})
+	})
+
+	It("returns ErrDocumentTooLarge for items larger than a shard", func() {
+		huge := []model.FirestoreSeasonSubject{{ID: 1, Summary: strings.Repeat("a", 2000)}}
+
+		_, err := m.UpdateSeasonalSubjects(ctx, "202504", huge)
+
+		Expect(errors.Is(err, ErrDocumentTooLarge)).To(BeTrue())
+	})
+})
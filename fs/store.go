@@ -0,0 +1,250 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// ops are the document operations shared by stores and transactions. Paths are slash separated
+// document paths such as "season/202504", values are read back in the generic form described in codec.go.
+type ops interface {
+	// get returns the document, or ErrDocumentDoesNotExist.
+	get(ctx context.Context, path string) (document, error)
+
+	// set writes the document, merging top level fields into an existing document if merge is set.
+	// firestore.ServerTimestamp values are resolved to the commit time.
+	set(ctx context.Context, path string, data interface{}, merge bool) error
+
+	// delete removes the document, deleting a missing document is not an error.
+	delete(ctx context.Context, path string) error
+
+	// query returns every document of the collection matching all filters. A nil fields returns whole
+	// documents, otherwise only the listed top level fields are returned, none for document ids only.
+	query(ctx context.Context, collection string, fields []string, filters ...filter) ([]document, error)
+}
+
+// store is the document backend behind Client.
+type store interface {
+	ops
+
+	// setAll overwrites several documents, keyed by path, without atomicity.
+	setAll(ctx context.Context, docs map[string]interface{}) error
+
+	// runTransaction runs fn atomically, every server timestamp resolves to the same commit time.
+	// Within fn all reads must happen before the first write, and fn may be called again on contention.
+	runTransaction(ctx context.Context, fn func(ctx context.Context, tx ops) error) error
+
+	// watch calls fn with the document, nil if it does not exist, first with its current state and then
+	// whenever it changes. Changes in quick succession may be delivered once. watch returns when ctx is
+	// done, when fn fails or when the listener breaks.
+	watch(ctx context.Context, path string, fn func(doc *document) error) error
+
+	// now returns the current time of the backend's clock.
+	now() time.Time
+
+	close() error
+}
+
+// document is a document read from a store.
+type document struct {
+	id   string
+	data map[string]interface{}
+
+	// dataTo decodes the document into the value v points to with the backend's own unmarshalling:
+	// DocumentSnapshot.DataTo for Firestore and the codec for the memory store.
+	dataTo func(v interface{}) error
+}
+
+func snapshotDocument(docSnap *firestore.DocumentSnapshot) document {
+	return document{id: docSnap.Ref.ID, data: docSnap.Data(), dataTo: docSnap.DataTo}
+}
+
+// filter is a query condition, op is one of ==, <, <=, > and >=.
+type filter struct {
+	field string
+	op    string
+	value interface{}
+}
+
+type firestoreStore struct {
+	fs *firestore.Client
+}
+
+func (s *firestoreStore) get(ctx context.Context, path string) (document, error) {
+	docSnap, err := s.fs.Doc(path).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return document{}, ErrDocumentDoesNotExist
+	}
+	if err != nil {
+		return document{}, err
+	}
+
+	if !docSnap.Exists() {
+		return document{}, ErrDocumentDoesNotExist
+	}
+
+	return snapshotDocument(docSnap), nil
+}
+
+func (s *firestoreStore) set(ctx context.Context, path string, data interface{}, merge bool) error {
+	var opts []firestore.SetOption
+	if merge {
+		opts = append(opts, firestore.MergeAll)
+	}
+
+	_, err := s.fs.Doc(path).Set(ctx, data, opts...)
+	return err
+}
+
+func (s *firestoreStore) setAll(ctx context.Context, docs map[string]interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	bw := s.fs.BulkWriter(ctx)
+
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+	for path, data := range docs {
+		job, err := bw.Set(s.fs.Doc(path), data)
+		if err != nil {
+			bw.End()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+
+	bw.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *firestoreStore) delete(ctx context.Context, path string) error {
+	_, err := s.fs.Doc(path).Delete(ctx)
+	return err
+}
+
+func (s *firestoreStore) query(ctx context.Context, collection string, fields []string, filters ...filter) ([]document, error) {
+	q := s.fs.Collection(collection).Query
+	if fields != nil {
+		q = q.Select(fields...)
+	}
+	for _, f := range filters {
+		q = q.Where(f.field, f.op, f.value)
+	}
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]document, 0, len(docs))
+	for _, doc := range docs {
+		results = append(results, snapshotDocument(doc))
+	}
+
+	return results, nil
+}
+
+func (s *firestoreStore) runTransaction(ctx context.Context, fn func(ctx context.Context, tx ops) error) error {
+	return s.fs.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		return fn(ctx, &firestoreTransaction{fs: s.fs, tx: tx})
+	})
+}
+
+func (s *firestoreStore) watch(ctx context.Context, path string, fn func(doc *document) error) error {
+	it := s.fs.Doc(path).Snapshots(ctx)
+	defer it.Stop()
+
+	for {
+		docSnap, err := it.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		var doc *document
+		if docSnap.Exists() {
+			d := snapshotDocument(docSnap)
+			doc = &d
+		}
+
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *firestoreStore) now() time.Time {
+	return time.Now()
+}
+
+func (s *firestoreStore) close() error {
+	return s.fs.Close()
+}
+
+type firestoreTransaction struct {
+	fs *firestore.Client
+	tx *firestore.Transaction
+}
+
+func (t *firestoreTransaction) get(ctx context.Context, path string) (document, error) {
+	docSnap, err := t.tx.Get(t.fs.Doc(path))
+	if status.Code(err) == codes.NotFound {
+		return document{}, ErrDocumentDoesNotExist
+	}
+	if err != nil {
+		return document{}, err
+	}
+
+	if !docSnap.Exists() {
+		return document{}, ErrDocumentDoesNotExist
+	}
+
+	return snapshotDocument(docSnap), nil
+}
+
+func (t *firestoreTransaction) set(ctx context.Context, path string, data interface{}, merge bool) error {
+	var opts []firestore.SetOption
+	if merge {
+		opts = append(opts, firestore.MergeAll)
+	}
+
+	return t.tx.Set(t.fs.Doc(path), data, opts...)
+}
+
+func (t *firestoreTransaction) delete(ctx context.Context, path string) error {
+	return t.tx.Delete(t.fs.Doc(path))
+}
+
+func (t *firestoreTransaction) query(ctx context.Context, collection string, fields []string, filters ...filter) ([]document, error) {
+	q := t.fs.Collection(collection).Query
+	if fields != nil {
+		q = q.Select(fields...)
+	}
+	for _, f := range filters {
+		q = q.Where(f.field, f.op, f.value)
+	}
+
+	docs, err := t.tx.Documents(q).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]document, 0, len(docs))
+	for _, doc := range docs {
+		results = append(results, snapshotDocument(doc))
+	}
+
+	return results, nil
+}
@@ -0,0 +1,12 @@
+package fs
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestFs(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "fs test suite")
+}
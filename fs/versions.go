@@ -0,0 +1,250 @@
+package fs
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	HistoryCollectionKey = "history"
+
+	FirebaseLastUpdatedByKey = "lastUpdatedBy"
+	FirebaseArchivedByKey    = "archivedBy"
+	FirebaseArchivedDateKey  = "archivedDate"
+
+	versionIDLayout = "20060102T150405.000000Z"
+)
+
+// versionFields are the fields of Version, listing versions does not read the archived data.
+var versionFields = []string{FirebaseLastUpdatedByKey, FirebaseLastUpdatedTimestampKey, FirebaseArchivedByKey, FirebaseArchivedDateKey}
+
+var ErrVersionDoesNotExist = errors.New("version does not exist")
+
+// Version is an archived version of a published document. It is stored in the document's history
+// subcollection as a copy of the document with the archive fields added.
+type Version struct {
+	ID string `firestore:"-"`
+
+	// Writer and PublishedDate describe the archived content, ArchivedBy and ArchivedDate the write
+	// that replaced it.
+	Writer        string    `firestore:"lastUpdatedBy"`
+	PublishedDate time.Time `firestore:"lastUpdatedDate"`
+	ArchivedBy    string    `firestore:"archivedBy"`
+	ArchivedDate  time.Time `firestore:"archivedDate"`
+}
+
+// ListVersions returns the archived versions of the document at path, newest first.
+func (c *Client) ListVersions(ctx context.Context, path string) ([]Version, error) {
+	docs, err := c.versionDocuments(ctx, c.store, path, versionFields)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]Version, 0, len(docs))
+	for _, doc := range docs {
+		var v Version
+		if err := doc.dataTo(&v); err != nil {
+			return nil, err
+		}
+		v.ID = doc.id
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+// Rollback republishes an archived version of the document at path. The current content is archived
+// first, so a rollback can itself be rolled back.
+func (c *Client) Rollback(ctx context.Context, path string, version string) error {
+	doc, err := c.read(ctx, c.store, versionPath(path, version))
+	if errors.Is(err, ErrDocumentDoesNotExist) {
+		return fmt.Errorf("%w: %s@%s", ErrVersionDoesNotExist, path, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	delete(doc, FirebaseArchivedByKey)
+	delete(doc, FirebaseArchivedDateKey)
+	doc[FirebaseLastUpdatedTimestampKey] = firestore.ServerTimestamp
+
+	_, err = c.publish(ctx, pendingDocument{path: path, data: doc})
+	return err
+}
+
+// publish archives the current version of a published document and writes the new one, unless its
+// content is unchanged. It is a batch of one document, so that the archive, the pruning of old versions
+// and the write are one transaction and concurrent writers never archive under the same version.
+// It returns the diff of the document.
+func (c *Client) publish(ctx context.Context, doc pendingDocument) (Diff, error) {
+	diffs, err := c.Publish(ctx, NewBatch().add(doc))
+	if err != nil {
+		return Diff{}, err
+	}
+
+	return diffs[0], nil
+}
+
+// publishPlan is a document to publish together with everything read before writing it.
+type publishPlan struct {
+	doc  pendingDocument
+	diff Diff
+
+	// shards are the shard ids of the document.
+	shards []string
+
+	// current is the document to archive as version, nil if there is nothing to archive.
+	current       map[string]interface{}
+	version       string
+	versionShards []string
+
+	// pruned maps the versions beyond the history limit to their shard ids.
+	pruned map[string][]string
+}
+
+// prepare does every read needed to publish doc and computes its diff.
+func (c *Client) prepare(ctx context.Context, o ops, doc pendingDocument) (*publishPlan, error) {
+	next, err := content(doc.data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := contentHash(next)
+	if err != nil {
+		return nil, err
+	}
+	doc.data[FirebaseContentHashKey] = hash
+
+	plan := &publishPlan{doc: doc}
+
+	raw, err := o.get(ctx, doc.path)
+	if errors.Is(err, ErrDocumentDoesNotExist) {
+		plan.diff = diff(doc.path, nil, next)
+		return plan, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if raw.data[FirebaseContentHashKey] == hash {
+		plan.diff = Diff{Path: doc.path, Skipped: true}
+		return plan, nil
+	}
+
+	current, err := assemble(ctx, o, doc.path, raw.data)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.shards, err = shardIDs(ctx, o, doc.path); err != nil {
+		return nil, err
+	}
+
+	if c.historyLimit > 0 {
+		if err := c.prepareArchive(ctx, o, plan, current); err != nil {
+			return nil, err
+		}
+	}
+
+	plan.diff = diff(doc.path, clone(current).(map[string]interface{}), next)
+
+	return plan, nil
+}
+
+// prepareArchive plans archiving the current document and pruning versions beyond the history limit.
+func (c *Client) prepareArchive(ctx context.Context, o ops, plan *publishPlan, current map[string]interface{}) error {
+	path := plan.doc.path
+
+	published, ok := current[FirebaseLastUpdatedTimestampKey].(time.Time)
+	if !ok {
+		published = c.store.now()
+	}
+	version := published.UTC().Format(versionIDLayout)
+
+	docs, err := c.versionDocuments(ctx, o, path, []string{})
+	if err != nil {
+		return err
+	}
+
+	ids := []string{version}
+	for _, d := range docs {
+		if d.id == version {
+			if plan.versionShards, err = shardIDs(ctx, o, versionPath(path, version)); err != nil {
+				return err
+			}
+			continue
+		}
+		ids = append(ids, d.id)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	plan.pruned = map[string][]string{}
+	for _, id := range ids[min(c.historyLimit, len(ids)):] {
+		if id == version {
+			continue
+		}
+		if plan.pruned[id], err = shardIDs(ctx, o, versionPath(path, id)); err != nil {
+			return err
+		}
+	}
+
+	// A version older than every kept version is not worth archiving.
+	if !slices.Contains(ids[min(c.historyLimit, len(ids)):], version) {
+		plan.current, plan.version = current, version
+	}
+
+	return nil
+}
+
+// apply does every write of a prepared plan, it does not read.
+func (c *Client) apply(ctx context.Context, o ops, plan *publishPlan) error {
+	if plan.diff.Skipped {
+		return nil
+	}
+
+	if plan.current != nil {
+		plan.current[FirebaseArchivedByKey] = c.writer
+		plan.current[FirebaseArchivedDateKey] = firestore.ServerTimestamp
+
+		if err := c.write(ctx, o, versionPath(plan.doc.path, plan.version), plan.current, false, plan.versionShards); err != nil {
+			return err
+		}
+	}
+
+	for id, shards := range plan.pruned {
+		path := versionPath(plan.doc.path, id)
+		if err := deleteShards(ctx, o, path, shards, nil); err != nil {
+			return err
+		}
+		if err := o.delete(ctx, path); err != nil {
+			return err
+		}
+	}
+
+	plan.doc.data[FirebaseLastUpdatedByKey] = c.writer
+
+	return c.write(ctx, o, plan.doc.path, plan.doc.data, plan.doc.merge, plan.shards)
+}
+
+// versionDocuments returns the fields of the history documents of path, newest first. Version ids sort by time.
+func (c *Client) versionDocuments(ctx context.Context, o ops, path string, fields []string) ([]document, error) {
+	docs, err := o.query(ctx, path+"/"+HistoryCollectionKey, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].id > docs[j].id
+	})
+
+	return docs, nil
+}
+
+func versionPath(path string, version string) string {
+	return DocumentPath(path+"/"+HistoryCollectionKey, version)
+}
@@ -13,8 +13,11 @@ require (
 	github.com/onsi/ginkgo/v2 v2.22.1
 	github.com/onsi/gomega v1.36.2
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/image v0.23.0
 	golang.org/x/text v0.21.0
 	google.golang.org/api v0.214.0
+	google.golang.org/grpc v1.67.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -55,8 +58,6 @@ require (
 	google.golang.org/genproto v0.0.0-20241118233622-e639e219e697 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20241118233622-e639e219e697 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20241209162323-e6fa225c2576 // indirect
-	google.golang.org/grpc v1.67.3 // indirect
 	google.golang.org/protobuf v1.36.1 // indirect
 	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,64 @@
+package history
+
+import (
+	"context"
+	"github.com/bangumilite/bangumilite-component/model"
+	"sync"
+	"time"
+)
+
+// Store persists subject snapshots. fs.Client is the Firestore backed implementation.
+type Store interface {
+	SaveSubjectSnapshots(ctx context.Context, snapshots []model.FirestoreSubjectSnapshot) error
+	GetSubjectSnapshots(ctx context.Context, since time.Time) ([]model.FirestoreSubjectSnapshot, error)
+
+	// GetSubjectSnapshotsByID returns the snapshots of one subject taken at or after since.
+	GetSubjectSnapshotsByID(ctx context.Context, subjectID int, since time.Time) ([]model.FirestoreSubjectSnapshot, error)
+}
+
+// MemoryStore keeps snapshots in memory, intended for tests and short-lived jobs.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	snapshots []model.FirestoreSubjectSnapshot
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) SaveSubjectSnapshots(_ context.Context, snapshots []model.FirestoreSubjectSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots = append(s.snapshots, snapshots...)
+
+	return nil
+}
+
+func (s *MemoryStore) GetSubjectSnapshots(_ context.Context, since time.Time) ([]model.FirestoreSubjectSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []model.FirestoreSubjectSnapshot
+	for _, snapshot := range s.snapshots {
+		if !snapshot.Timestamp.Before(since) {
+			res = append(res, snapshot)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *MemoryStore) GetSubjectSnapshotsByID(_ context.Context, subjectID int, since time.Time) ([]model.FirestoreSubjectSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []model.FirestoreSubjectSnapshot
+	for _, snapshot := range s.snapshots {
+		if snapshot.SubjectID == subjectID && !snapshot.Timestamp.Before(since) {
+			res = append(res, snapshot)
+		}
+	}
+
+	return res, nil
+}
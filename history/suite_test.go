@@ -0,0 +1,12 @@
+package history
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestHistory(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "history test suite")
+}
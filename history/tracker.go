@@ -0,0 +1,194 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/bangumi"
+	"github.com/bangumilite/bangumilite-component/model"
+	"sort"
+	"time"
+)
+
+var ErrNotEnoughSnapshots = errors.New("not enough snapshots in the window")
+
+// Change compares the earliest and the latest snapshot of a subject within a window.
+type Change struct {
+	SubjectID  int
+	From       model.FirestoreSubjectSnapshot
+	To         model.FirestoreSubjectSnapshot
+	ScoreDelta float64
+	RankDelta  int // positive when the subject climbed, i.e. its rank number decreased
+}
+
+type Tracker struct {
+	store Store
+	now   func() time.Time
+}
+
+func NewTracker(store Store) *Tracker {
+	return &Tracker{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// SetClock overrides the clock used to timestamp snapshots and to resolve query windows.
+func (t *Tracker) SetClock(now func() time.Time) {
+	t.now = now
+}
+
+// Record stores a snapshot of each subject taken at the current time.
+func (t *Tracker) Record(ctx context.Context, subjects []model.BangumiSubject) error {
+	if len(subjects) == 0 {
+		return nil
+	}
+
+	now := t.now()
+	snapshots := make([]model.FirestoreSubjectSnapshot, len(subjects))
+	for i, subject := range subjects {
+		snapshots[i] = model.FirestoreSubjectSnapshot{
+			SubjectID:  subject.ID,
+			Timestamp:  now,
+			Score:      subject.Rating.Score,
+			Rank:       subject.Rating.Rank,
+			Collection: subject.Collection,
+		}
+	}
+
+	return t.store.SaveSubjectSnapshots(ctx, snapshots)
+}
+
+// ScoreChange returns how the score of a subject moved over the given window, e.g. the last 7 days.
+func (t *Tracker) ScoreChange(ctx context.Context, subjectID int, window time.Duration) (*Change, error) {
+	snapshots, err := t.store.GetSubjectSnapshotsByID(ctx, subjectID, t.now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := seriesChanges(snapshots, func(s model.FirestoreSubjectSnapshot) bool {
+		return s.SubjectID == subjectID
+	})
+	if len(changes) == 0 {
+		return nil, ErrNotEnoughSnapshots
+	}
+
+	return &changes[0], nil
+}
+
+// RankClimbers returns the subjects whose rank improved the most over the given window, best first.
+// Unranked snapshots are ignored and limit <= 0 returns every climber.
+func (t *Tracker) RankClimbers(ctx context.Context, window time.Duration, limit int) ([]Change, error) {
+	snapshots, err := t.store.GetSubjectSnapshots(ctx, t.now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := seriesChanges(snapshots, func(s model.FirestoreSubjectSnapshot) bool {
+		return s.Rank > 0
+	})
+
+	var climbers []Change
+	for _, change := range changes {
+		if change.RankDelta > 0 {
+			climbers = append(climbers, change)
+		}
+	}
+
+	sort.SliceStable(climbers, func(i, j int) bool {
+		if climbers[i].RankDelta != climbers[j].RankDelta {
+			return climbers[i].RankDelta > climbers[j].RankDelta
+		}
+		return climbers[i].SubjectID < climbers[j].SubjectID
+	})
+
+	if limit > 0 && len(climbers) > limit {
+		climbers = climbers[:limit]
+	}
+
+	return climbers, nil
+}
+
+// seriesChanges builds one Change per subject having at least two kept snapshots.
+func seriesChanges(snapshots []model.FirestoreSubjectSnapshot, keep func(model.FirestoreSubjectSnapshot) bool) []Change {
+	bySubject := make(map[int][]model.FirestoreSubjectSnapshot)
+	var ids []int
+	for _, snapshot := range snapshots {
+		if !keep(snapshot) {
+			continue
+		}
+
+		if _, ok := bySubject[snapshot.SubjectID]; !ok {
+			ids = append(ids, snapshot.SubjectID)
+		}
+		bySubject[snapshot.SubjectID] = append(bySubject[snapshot.SubjectID], snapshot)
+	}
+
+	var changes []Change
+	for _, id := range ids {
+		series := bySubject[id]
+		if len(series) < 2 {
+			continue
+		}
+
+		sort.SliceStable(series, func(i, j int) bool {
+			return series[i].Timestamp.Before(series[j].Timestamp)
+		})
+
+		from, to := series[0], series[len(series)-1]
+		changes = append(changes, Change{
+			SubjectID:  id,
+			From:       from,
+			To:         to,
+			ScoreDelta: to.Score - from.Score,
+			RankDelta:  from.Rank - to.Rank,
+		})
+	}
+
+	return changes
+}
+
+// recordingFetcher records a snapshot of every subject fetched through it.
+type recordingFetcher struct {
+	fetcher bangumi.SubjectFetcher
+	tracker *Tracker
+	onError func(error)
+}
+
+// NewRecordingFetcher wraps a SubjectFetcher so that each fetch is recorded by the tracker.
+// Failing to record does not fail the fetch, the error is reported to onError if it is not nil.
+func NewRecordingFetcher(fetcher bangumi.SubjectFetcher, tracker *Tracker, onError func(error)) bangumi.SubjectFetcher {
+	return &recordingFetcher{
+		fetcher: fetcher,
+		tracker: tracker,
+		onError: onError,
+	}
+}
+
+func (f *recordingFetcher) GetSubject(ctx context.Context, id int, opts ...bangumi.RequestOption) (*model.BangumiSubject, error) {
+	subject, err := f.fetcher.GetSubject(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	f.record(ctx, []model.BangumiSubject{*subject})
+
+	return subject, nil
+}
+
+func (f *recordingFetcher) GetSubjects(ctx context.Context, ids []int, opts ...bangumi.RequestOption) ([]model.BangumiSubject, error) {
+	subjects, err := f.fetcher.GetSubjects(ctx, ids, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	f.record(ctx, subjects)
+
+	return subjects, nil
+}
+
+func (f *recordingFetcher) record(ctx context.Context, subjects []model.BangumiSubject) {
+	err := f.tracker.Record(ctx, subjects)
+	if err != nil && f.onError != nil {
+		f.onError(err)
+	}
+}
@@ -0,0 +1,144 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/bangumi/bangumifakes"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+// subjectOnlyStore fails queries over every subject, so that specs can assert a subject query is used.
+type subjectOnlyStore struct {
+	*MemoryStore
+}
+
+func (s subjectOnlyStore) GetSubjectSnapshots(context.Context, time.Time) ([]model.FirestoreSubjectSnapshot, error) {
+	return nil, errors.New("queried every subject")
+}
+
+func subject(id int, score float64, rank int) model.BangumiSubject {
+	return model.BangumiSubject{
+		ID:     id,
+		Rating: model.BangumiRating{Score: score, Rank: rank},
+	}
+}
+
+var _ = Describe("history tracker unit tests", func() {
+	var (
+		ctx     context.Context
+		now     time.Time
+		store   *MemoryStore
+		tracker *Tracker
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+		store = NewMemoryStore()
+		tracker = NewTracker(store)
+		tracker.SetClock(func() time.Time { return now })
+	})
+
+	record := func(at time.Time, subjects ...model.BangumiSubject) {
+		now = at
+		Expect(tracker.Record(ctx, subjects)).To(Succeed())
+	}
+
+	Describe("ScoreChange", func() {
+		It("compares the earliest and latest snapshot within the window", func() {
+			start := now
+			record(start.Add(-10*24*time.Hour), subject(1, 6.0, 900))
+			record(start.Add(-6*24*time.Hour), subject(1, 7.0, 800))
+			record(start, subject(1, 7.5, 700))
+
+			got, err := tracker.ScoreChange(ctx, 1, 7*24*time.Hour)
+
+			Expect(err).To(BeNil())
+			Expect(got.ScoreDelta).To(BeNumerically("~", 0.5))
+			Expect(got.RankDelta).To(Equal(100))
+		})
+
+		It("queries the snapshots of the subject only", func() {
+			tracker = NewTracker(subjectOnlyStore{store})
+			tracker.SetClock(func() time.Time { return now })
+
+			start := now
+			record(start.Add(-24*time.Hour), subject(1, 6.0, 900), subject(2, 5.0, 1000))
+			record(start, subject(1, 7.0, 800), subject(2, 8.0, 100))
+
+			got, err := tracker.ScoreChange(ctx, 1, 7*24*time.Hour)
+
+			Expect(err).To(BeNil())
+			Expect(got.SubjectID).To(Equal(1))
+			Expect(got.ScoreDelta).To(BeNumerically("~", 1.0))
+		})
+
+		It("returns error if there are fewer than two snapshots", func() {
+			record(now, subject(1, 7.5, 700))
+
+			got, err := tracker.ScoreChange(ctx, 1, 7*24*time.Hour)
+
+			Expect(err).To(Equal(ErrNotEnoughSnapshots))
+			Expect(got).To(BeNil())
+		})
+	})
+
+	Describe("RankClimbers", func() {
+		It("returns climbers ordered by rank improvement", func() {
+			start := now
+			record(start.Add(-5*24*time.Hour), subject(1, 7, 500), subject(2, 7, 300), subject(3, 7, 100), subject(4, 0, 0))
+			record(start, subject(1, 7, 200), subject(2, 7, 250), subject(3, 7, 120), subject(4, 6, 50))
+
+			got, err := tracker.RankClimbers(ctx, 7*24*time.Hour, 0)
+
+			Expect(err).To(BeNil())
+			Expect(got).To(HaveLen(2))
+			Expect(got[0].SubjectID).To(Equal(1))
+			Expect(got[0].RankDelta).To(Equal(300))
+			Expect(got[1].SubjectID).To(Equal(2))
+		})
+
+		It("applies the limit", func() {
+			start := now
+			record(start.Add(-24*time.Hour), subject(1, 7, 500), subject(2, 7, 300))
+			record(start, subject(1, 7, 200), subject(2, 7, 250))
+
+			got, err := tracker.RankClimbers(ctx, 7*24*time.Hour, 1)
+
+			Expect(err).To(BeNil())
+			Expect(got).To(HaveLen(1))
+		})
+	})
+
+	Describe("NewRecordingFetcher", func() {
+		It("records every fetched subject", func() {
+			fake := &bangumifakes.FakeSubjectFetcher{}
+			fake.GetSubjectsReturns([]model.BangumiSubject{subject(1, 7, 10), subject(2, 8, 5)}, nil)
+
+			fetcher := NewRecordingFetcher(fake, tracker, nil)
+			_, err := fetcher.GetSubjects(ctx, []int{1, 2})
+			Expect(err).To(BeNil())
+
+			snapshots, err := store.GetSubjectSnapshots(ctx, time.Time{})
+			Expect(err).To(BeNil())
+			Expect(snapshots).To(HaveLen(2))
+			Expect(snapshots[0].Timestamp).To(Equal(now))
+		})
+
+		It("does not record when the fetch fails", func() {
+			fake := &bangumifakes.FakeSubjectFetcher{}
+			fake.GetSubjectReturns(nil, errors.New("failed"))
+
+			fetcher := NewRecordingFetcher(fake, tracker, nil)
+			_, err := fetcher.GetSubject(ctx, 1)
+			Expect(err).ToNot(BeNil())
+
+			snapshots, err := store.GetSubjectSnapshots(ctx, time.Time{})
+			Expect(err).To(BeNil())
+			Expect(snapshots).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,230 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/bangumi"
+	"github.com/bangumilite/bangumilite-component/httplib"
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/go-resty/resty/v2"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+const (
+	MaxConcurrentDownloads = 10
+
+	DefaultExtension = ".jpg"
+)
+
+var ErrEmptyImage = errors.New("image url is empty")
+
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Mirror downloads remote images and stores them in a BlobStore, keyed by the sha256 of their content.
+type Mirror struct {
+	client *resty.Client
+	store  BlobStore
+
+	mu    sync.Mutex
+	cache map[string]string // source url -> mirrored url, avoids downloading the same image twice per run
+}
+
+func New(store BlobStore) *Mirror {
+	return &Mirror{
+		client: httplib.NewClient(),
+		store:  store,
+		cache:  make(map[string]string),
+	}
+}
+
+// MirrorImage downloads the image at src and returns its mirrored URL.
+// The blob is only uploaded if the store does not already hold the same content.
+func (m *Mirror) MirrorImage(ctx context.Context, src string) (string, error) {
+	if len(src) == 0 {
+		return "", ErrEmptyImage
+	}
+
+	url := normalizeURL(src)
+
+	m.mu.Lock()
+	mirrored, ok := m.cache[url]
+	m.mu.Unlock()
+	if ok {
+		return mirrored, nil
+	}
+
+	resp, err := m.client.R().
+		SetContext(ctx).
+		SetHeader("User-Agent", bangumi.UserAgentHeader).
+		Get(url)
+
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s, status code: %d", url, resp.StatusCode())
+	}
+
+	body := resp.Body()
+	contentType := resp.Header().Get("Content-Type")
+	key := blobKey(body, contentType, url)
+
+	exists, err := m.store.Exists(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if !exists {
+		err = m.store.Put(ctx, key, contentType, bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	mirrored = m.store.URL(key)
+
+	m.mu.Lock()
+	m.cache[url] = mirrored
+	m.mu.Unlock()
+
+	return mirrored, nil
+}
+
+// MirrorSubjects rewrites the image of each subject to its mirrored URL.
+// Subjects whose image fails to mirror keep the original URL and the errors are joined.
+func (m *Mirror) MirrorSubjects(ctx context.Context, subjects []model.FirestoreSubject) error {
+	images := make([]*string, len(subjects))
+	for i := range subjects {
+		images[i] = &subjects[i].Image
+	}
+
+	return m.rewrite(ctx, images)
+}
+
+// MirrorSeasonIndex rewrites the image of each season index item to its mirrored URL. Items without a
+// source record their original image as source, so that season.MergeIndex can still compare them.
+func (m *Mirror) MirrorSeasonIndex(ctx context.Context, items []model.FirestoreSeasonIndexItem) error {
+	images := make([]*string, len(items))
+	for i := range items {
+		if len(items[i].Source) == 0 && !m.isMirrored(items[i].Image) {
+			items[i].Source = items[i].Image
+		}
+		images[i] = &items[i].Image
+	}
+
+	return m.rewrite(ctx, images)
+}
+
+// MirrorMonos rewrites the image of each mono to its mirrored URL.
+func (m *Mirror) MirrorMonos(ctx context.Context, monos []model.FirestoreMono) error {
+	var images []*string
+	for i := range monos {
+		if monos[i].Image != nil {
+			images = append(images, monos[i].Image)
+		}
+	}
+
+	return m.rewrite(ctx, images)
+}
+
+// MirrorMonoDocument rewrites the images of every mono list in the document.
+func (m *Mirror) MirrorMonoDocument(ctx context.Context, doc *model.FirestoreMonoDocument) error {
+	return errors.Join(
+		m.MirrorMonos(ctx, doc.Trending),
+		m.MirrorMonos(ctx, doc.Popular),
+		m.MirrorMonos(ctx, doc.Birthday),
+		m.MirrorMonos(ctx, doc.Inventory),
+	)
+}
+
+func (m *Mirror) rewrite(ctx context.Context, images []*string) error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, MaxConcurrentDownloads)
+		errs []error
+	)
+
+	for _, image := range images {
+		if len(*image) == 0 || m.isMirrored(*image) {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(image *string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			mirrored, err := m.MirrorImage(ctx, *image)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+
+			*image = mirrored
+		}(image)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (m *Mirror) isMirrored(image string) bool {
+	return strings.HasPrefix(image, m.store.URL(""))
+}
+
+// blobKey builds a content-addressed key such as "ab/abcdef....jpg".
+func blobKey(body []byte, contentType string, url string) string {
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+
+	return fmt.Sprintf("%s/%s%s", hash[:2], hash, extension(contentType, url))
+}
+
+func extension(contentType string, url string) string {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	if ext, ok := extensions[mediaType]; ok {
+		return ext
+	}
+
+	ext := strings.ToLower(path.Ext(url))
+	for _, known := range extensions {
+		if ext == known {
+			return ext
+		}
+	}
+
+	if ext == ".jpeg" {
+		return ".jpg"
+	}
+
+	return DefaultExtension
+}
+
+func normalizeURL(src string) string {
+	if strings.HasPrefix(src, "//") {
+		return "https:" + src
+	}
+
+	return src
+}
@@ -0,0 +1,146 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var _ = Describe("mirror unit tests", func() {
+	var (
+		dir    string
+		store  *LocalStore
+		mirror *Mirror
+	)
+
+	respondImage := func(body string) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(200, body)
+			resp.Header.Add("Content-Type", "image/jpeg")
+			return resp, nil
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "mirror")
+		Expect(err).To(BeNil())
+
+		store, err = NewLocalStore(dir, "https://cdn.example.com/covers/")
+		Expect(err).To(BeNil())
+
+		mirror = New(store)
+		httpmock.ActivateNonDefault(mirror.client.GetClient())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Describe("MirrorImage", func() {
+		It("stores the image under its content hash", func() {
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/l/1.jpg", respondImage("cover"))
+
+			got, err := mirror.MirrorImage(context.Background(), "//lain.bgm.tv/pic/cover/l/1.jpg")
+
+			Expect(err).To(BeNil())
+			sum := sha256.Sum256([]byte("cover"))
+			hash := hex.EncodeToString(sum[:])
+			Expect(got).To(Equal("https://cdn.example.com/covers/" + hash[:2] + "/" + hash + ".jpg"))
+
+			key := got[len("https://cdn.example.com/covers/"):]
+			content, err := os.ReadFile(filepath.Join(dir, key))
+			Expect(err).To(BeNil())
+			Expect(string(content)).To(Equal("cover"))
+		})
+
+		It("returns error if the image cannot be downloaded", func() {
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/l/404.jpg", httpmock.NewStringResponder(404, ""))
+
+			got, err := mirror.MirrorImage(context.Background(), "https://lain.bgm.tv/pic/cover/l/404.jpg")
+
+			Expect(err).ToNot(BeNil())
+			Expect(got).To(BeEmpty())
+		})
+
+		It("returns error if the image is empty", func() {
+			_, err := mirror.MirrorImage(context.Background(), "")
+
+			Expect(err).To(Equal(ErrEmptyImage))
+		})
+	})
+
+	Describe("MirrorSubjects", func() {
+		It("rewrites images and reuses identical blobs", func() {
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/l/1.jpg", respondImage("same"))
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/l/2.jpg", respondImage("same"))
+
+			subjects := []model.FirestoreSubject{
+				{ID: 1, Image: "https://lain.bgm.tv/pic/cover/l/1.jpg"},
+				{ID: 2, Image: "https://lain.bgm.tv/pic/cover/l/2.jpg"},
+				{ID: 3},
+			}
+
+			err := mirror.MirrorSubjects(context.Background(), subjects)
+
+			Expect(err).To(BeNil())
+			Expect(subjects[0].Image).To(HavePrefix("https://cdn.example.com/covers/"))
+			Expect(subjects[1].Image).To(Equal(subjects[0].Image))
+			Expect(subjects[2].Image).To(BeEmpty())
+		})
+
+		It("keeps the original image if mirroring fails", func() {
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/l/1.jpg", httpmock.NewStringResponder(404, ""))
+
+			subjects := []model.FirestoreSubject{
+				{ID: 1, Image: "https://lain.bgm.tv/pic/cover/l/1.jpg"},
+			}
+
+			err := mirror.MirrorSubjects(context.Background(), subjects)
+
+			Expect(err).ToNot(BeNil())
+			Expect(subjects[0].Image).To(Equal("https://lain.bgm.tv/pic/cover/l/1.jpg"))
+		})
+	})
+
+	Describe("MirrorSeasonIndex", func() {
+		It("records the original image as source", func() {
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/l/1.jpg", respondImage("season"))
+
+			items := []model.FirestoreSeasonIndexItem{
+				{ID: "202504", Image: "https://lain.bgm.tv/pic/cover/l/1.jpg"},
+			}
+
+			err := mirror.MirrorSeasonIndex(context.Background(), items)
+
+			Expect(err).To(BeNil())
+			Expect(items[0].Image).To(HavePrefix("https://cdn.example.com/covers/"))
+			Expect(items[0].Source).To(Equal("https://lain.bgm.tv/pic/cover/l/1.jpg"))
+		})
+	})
+
+	Describe("MirrorMonos", func() {
+		It("rewrites mono images and skips monos without image", func() {
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/crt/l/1.jpg", respondImage("mono"))
+
+			image := "https://lain.bgm.tv/pic/crt/l/1.jpg"
+			monos := []model.FirestoreMono{
+				{ID: 1, Image: &image},
+				{ID: 2},
+			}
+
+			err := mirror.MirrorMonos(context.Background(), monos)
+
+			Expect(err).To(BeNil())
+			Expect(*monos[0].Image).To(HavePrefix("https://cdn.example.com/covers/"))
+			Expect(monos[1].Image).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore persists mirrored images under content-addressed keys.
+type BlobStore interface {
+	// Exists reports whether a blob with the given key has already been stored.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Put stores the blob read from r under the given key.
+	Put(ctx context.Context, key string, contentType string, r io.Reader) error
+	// URL returns the public URL the blob is served from.
+	URL(key string) string
+}
+
+// LocalStore is a BlobStore backed by a local directory, typically served by a static file server.
+type LocalStore struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStore(dir string, baseURL string) (*LocalStore, error) {
+	if len(dir) == 0 {
+		return nil, errors.New("local store directory cannot be empty")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+func (s *LocalStore) Exists(_ context.Context, key string) (bool, error) {
+	_, err := os.Stat(s.path(key))
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (s *LocalStore) Put(_ context.Context, key string, _ string, r io.Reader) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never see a partially written blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStore) URL(key string) string {
+	return fmt.Sprintf("%s/%s", s.baseURL, key)
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
@@ -0,0 +1,21 @@
+package mirror
+
+import (
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestMirror(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "mirror test suite")
+}
+
+var _ = BeforeEach(func() {
+	httpmock.Reset()
+})
+
+var _ = AfterSuite(func() {
+	httpmock.DeactivateAndReset()
+})
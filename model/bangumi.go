@@ -1,6 +1,10 @@
 package model
 
-import "strings"
+import (
+	"github.com/bangumilite/bangumilite-component/tag"
+	"strings"
+	"time"
+)
 
 type BangumiTags []BangumiTag
 
@@ -10,12 +14,39 @@ type BangumiSubject struct {
 	Name       string            `json:"name" firestore:"name"`
 	NameCn     string            `json:"name_cn" firestore:"name_cn"`
 	Summary    string            `json:"summary" firestore:"summary"`
+	Date       string            `json:"date,omitempty" firestore:"date,omitempty"`
+	Eps        int               `json:"eps,omitempty" firestore:"eps,omitempty"`
 	Images     BangumiImages     `json:"images" firestore:"images"`
 	Collection BangumiCollection `json:"collection" firestore:"collection"`
 	Tags       BangumiTags       `json:"tags" firestore:"tags"`
 	Rating     BangumiRating     `json:"rating" firestore:"rating"`
 }
 
+// AirDate parses the "2006-01-02" air date of the subject, reporting false if it is missing or malformed.
+func (s BangumiSubject) AirDate() (time.Time, bool) {
+	t, err := time.Parse(time.DateOnly, s.Date)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// ToFirestoreSubject converts the subject into the summary published in Firestore lists.
+func (s BangumiSubject) ToFirestoreSubject() FirestoreSubject {
+	return FirestoreSubject{
+		ID:         s.ID,
+		Name:       s.Name,
+		NameCn:     s.NameCn,
+		Image:      s.Images.Large,
+		Info:       s.Tags.ToString(),
+		Score:      s.Rating.Score,
+		Rank:       s.Rating.Rank,
+		Collection: s.Collection.Total(),
+		Type:       s.Type,
+	}
+}
+
 type BangumiImages struct {
 	Small  string `json:"small" firestore:"small"`
 	Medium string `json:"medium" firestore:"medium"`
@@ -34,23 +65,29 @@ func (c BangumiCollection) Total() int {
 	return c.Wish + c.Collect + c.Doing + c.OnHold + c.Dropped
 }
 
-type BangumiTag struct {
-	Name string `json:"name" firestore:"name"`
-}
+// BangumiTag is a tag with its vote count, it is defined by the tag package that normalises tags.
+type BangumiTag = tag.Tag
 
+// ToString joins the five most voted tags, skipping year and format noise tags.
 func (t BangumiTags) ToString() string {
 	var tags strings.Builder
 
-	for i := 0; i < len(t) && i < 5; i++ {
+	for i, tag := range t.TopTags(5) {
 		if i > 0 {
 			tags.WriteString(" / ")
 		}
-		tags.WriteString(t[i].Name)
+		tags.WriteString(tag.Name)
 	}
 
 	return tags.String()
 }
 
+// TopTags returns at most n tags ordered by count, skipping noise tags and the excluded names. Tag
+// variants are merged and compared by tag.DefaultNormalizer.
+func (t BangumiTags) TopTags(n int, exclude ...string) BangumiTags {
+	return tag.DefaultNormalizer.TopTags(t, n, exclude...)
+}
+
 type BangumiRating struct {
 	Rank  int     `json:"rank" firestore:"rank"`
 	Score float64 `json:"score" firestore:"score"`
@@ -65,6 +102,63 @@ type BangumiPerson struct {
 	Name string `json:"name" firestore:"name"`
 }
 
+type BangumiSubjectComment struct {
+	UserID   string    `json:"user_id" firestore:"user_id"`
+	UserName string    `json:"user_name" firestore:"user_name"`
+	Avatar   string    `json:"avatar" firestore:"avatar"`
+	Rating   int       `json:"rating,omitempty" firestore:"rating,omitempty"`
+	Status   string    `json:"status,omitempty" firestore:"status,omitempty"`
+	Time     time.Time `json:"time" firestore:"time"`
+	Text     string    `json:"text" firestore:"text"`
+}
+
+type BangumiEpisode struct {
+	ID      int     `json:"id" firestore:"id"`
+	Type    int     `json:"type" firestore:"type"`
+	Sort    float64 `json:"sort" firestore:"sort"`
+	Ep      float64 `json:"ep" firestore:"ep"`
+	Airdate string  `json:"airdate" firestore:"airdate"`
+}
+
+// AirDate parses the "2006-01-02" air date of the episode, reporting false if it is missing or malformed.
+func (e BangumiEpisode) AirDate() (time.Time, bool) {
+	t, err := time.Parse(time.DateOnly, e.Airdate)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+type BangumiEpisodesResponse struct {
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+	Data   []BangumiEpisode `json:"data"`
+}
+
+type BangumiSearchRequest struct {
+	Keyword string              `json:"keyword"`
+	Sort    string              `json:"sort,omitempty"`
+	Filter  BangumiSearchFilter `json:"filter"`
+}
+
+type BangumiSearchFilter struct {
+	Type    []int    `json:"type,omitempty"`
+	Tag     []string `json:"tag,omitempty"`
+	AirDate []string `json:"air_date,omitempty"`
+	Rating  []string `json:"rating,omitempty"`
+	Rank    []string `json:"rank,omitempty"`
+	NSFW    *bool    `json:"nsfw,omitempty"`
+}
+
+type BangumiSearchResponse struct {
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+	Data   []BangumiSubject `json:"data"`
+}
+
 type BangumiOAuthResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -85,6 +179,9 @@ type BangumiGenericErrorResponse struct {
 
 type SubjectTypeID string
 
+// EpisodeTypeMain is the type of regular episodes, as opposed to specials, openings and endings.
+const EpisodeTypeMain = 0
+
 const (
 	BookID  SubjectTypeID = "1"
 	AnimeID SubjectTypeID = "2"
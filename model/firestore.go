@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"time"
 )
 
 type FirestoreBangumiToken struct {
@@ -22,6 +23,9 @@ type FirestoreSubject struct {
 	Rank       int     `firestore:"rank,omitempty" json:"rank"`
 	Collection int     `firestore:"collection" json:"collection"`
 	Type       int     `firestore:"type,omitempty" json:"type,omitempty"`
+
+	DominantColor string `firestore:"dominant_color,omitempty" json:"dominant_color,omitempty"`
+	BlurHash      string `firestore:"blurhash,omitempty" json:"blurhash,omitempty"`
 }
 
 type FirestoreSeasonSubject struct {
@@ -34,6 +38,9 @@ type FirestoreSeasonSubject struct {
 	Actors   []BangumiPerson    `firestore:"actors" json:"actors"`
 	Staff    []string           `firestore:"staff" json:"staff"`
 	Trailers []FirestoreTrailer `firestore:"trailers" json:"trailers"`
+
+	// Continuing marks shows carried over from a previous season.
+	Continuing bool `firestore:"continuing,omitempty" json:"continuing,omitempty"`
 }
 
 type FirestoreTrailer struct {
@@ -41,15 +48,33 @@ type FirestoreTrailer struct {
 	Thumbnail string `json:"thumbnail" firestore:"thumbnail"`
 }
 
+// FirestoreDocument is a published document, the data and the server time of the last update.
+type FirestoreDocument[T any] struct {
+	Data            T         `firestore:"data" json:"data"`
+	LastUpdatedDate time.Time `firestore:"lastUpdatedDate" json:"lastUpdatedDate"`
+}
+
 type FirestoreSeasonIndexDocument struct {
-	Data []FirestoreSeasonIndexItem `firestore:"data" json:"data"`
+	Data            []FirestoreSeasonIndexItem `firestore:"data" json:"data"`
+	LastUpdatedDate time.Time                  `firestore:"lastUpdatedDate" json:"lastUpdatedDate"`
 }
 
 type FirestoreSeasonIndexItem struct {
 	ID    string `firestore:"id" json:"id"`
 	Image string `firestore:"image" json:"image"`
+
+	// Source is the upstream URL of Image, which keeps it after Image is rewritten to a mirrored URL.
+	Source string `firestore:"source,omitempty" json:"source,omitempty"`
+
+	DominantColor string `firestore:"dominant_color,omitempty" json:"dominant_color,omitempty"`
+	BlurHash      string `firestore:"blurhash,omitempty" json:"blurhash,omitempty"`
 }
 
+// SeasonID is the stored form of a season such as "202504", use it for season fields of stored models.
+// season.Season has no exported fields and would be stored as an empty map, so convert it with
+// Season.Firestore and restore it with season.FromFirestore.
+type SeasonID string
+
 type FirestoreDiscoverySubject struct {
 	Title string             `firestore:"title" json:"title"`
 	Data  []FirestoreSubject `firestore:"data" json:"data"`
@@ -80,6 +105,15 @@ type FirestoreMonoRelatedWork struct {
 	Mono     *FirestoreMono `json:"mono" firestore:"mono,omitempty"`
 }
 
+// FirestoreSubjectSnapshot records the rating and collection totals of a subject at a point in time.
+type FirestoreSubjectSnapshot struct {
+	SubjectID  int               `firestore:"subject_id" json:"subject_id"`
+	Timestamp  time.Time         `firestore:"timestamp" json:"timestamp"`
+	Score      float64           `firestore:"score" json:"score"`
+	Rank       int               `firestore:"rank" json:"rank"`
+	Collection BangumiCollection `firestore:"collection" json:"collection"`
+}
+
 func (m FirestoreMonoDocument) Validate() error {
 	if len(m.Trending) == 0 {
 		return errors.New("trending mono is empty")
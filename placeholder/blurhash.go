@@ -0,0 +1,142 @@
+package placeholder
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// encodeBlurHash implements the BlurHash algorithm (https://blurha.sh) for the given number of components.
+func encodeBlurHash(img image.Image, componentsX int, componentsY int) string {
+	pixels := samplePixels(img)
+	height := len(pixels)
+	width := len(pixels[0])
+
+	factors := make([][3]float64, 0, componentsX*componentsY)
+	for j := 0; j < componentsY; j++ {
+		for i := 0; i < componentsX; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1.0
+			}
+
+			var r, g, b float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+
+					p := pixels[y][x]
+					r += basis * p[0]
+					g += basis * p[1]
+					b += basis * p[2]
+				}
+			}
+
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	var hash strings.Builder
+
+	sizeFlag := (componentsX - 1) + (componentsY-1)*9
+	hash.WriteString(encode83(sizeFlag, 1))
+
+	dc := factors[0]
+	ac := factors[1:]
+
+	maximumValue := 1.0
+	if len(ac) > 0 {
+		actualMaximumValue := 0.0
+		for _, f := range ac {
+			actualMaximumValue = math.Max(actualMaximumValue, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+
+		quantisedMaximumValue := int(math.Max(0, math.Min(82, math.Floor(actualMaximumValue*166-0.5))))
+		maximumValue = float64(quantisedMaximumValue+1) / 166
+		hash.WriteString(encode83(quantisedMaximumValue, 1))
+	} else {
+		hash.WriteString(encode83(0, 1))
+	}
+
+	hash.WriteString(encode83(encodeDC(dc), 4))
+
+	for _, f := range ac {
+		hash.WriteString(encode83(encodeAC(f, maximumValue), 2))
+	}
+
+	return hash.String()
+}
+
+func encodeDC(c [3]float64) int {
+	return linearToSRGB(c[0])<<16 + linearToSRGB(c[1])<<8 + linearToSRGB(c[2])
+}
+
+func encodeAC(c [3]float64, maximumValue float64) int {
+	quant := func(v float64) int {
+		return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+	}
+
+	return quant(c[0])*19*19 + quant(c[1])*19 + quant(c[2])
+}
+
+func encode83(value int, length int) string {
+	var result strings.Builder
+
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		result.WriteByte(base83Chars[digit])
+	}
+
+	return result.String()
+}
+
+// samplePixels converts the image into linear RGB, downsampled so that neither side exceeds maxSampleSize.
+func samplePixels(img image.Image) [][][3]float64 {
+	bounds := img.Bounds()
+	width := min(bounds.Dx(), maxSampleSize)
+	height := min(bounds.Dy(), maxSampleSize)
+
+	pixels := make([][][3]float64, height)
+	for y := 0; y < height; y++ {
+		pixels[y] = make([][3]float64, width)
+		for x := 0; x < width; x++ {
+			srcX := bounds.Min.X + x*bounds.Dx()/width
+			srcY := bounds.Min.Y + y*bounds.Dy()/height
+
+			r, g, b, _ := img.At(srcX, srcY).RGBA()
+			pixels[y][x] = [3]float64{
+				sRGBToLinear(int(r >> 8)),
+				sRGBToLinear(int(g >> 8)),
+				sRGBToLinear(int(b >> 8)),
+			}
+		}
+	}
+
+	return pixels
+}
+
+func sRGBToLinear(value int) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value float64, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
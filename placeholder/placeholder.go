@@ -0,0 +1,232 @@
+package placeholder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/bangumi"
+	"github.com/bangumilite/bangumilite-component/httplib"
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/go-resty/resty/v2"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	BlurHashComponentsX = 4
+	BlurHashComponentsY = 3
+
+	MaxConcurrentDownloads = 10
+
+	// maxSampleSize caps the sampled pixels per side, covers are analysed at thumbnail size anyway.
+	maxSampleSize = 64
+
+	// colorBits is the number of bits per channel kept when bucketing pixels for the dominant colour.
+	colorBits = 4
+)
+
+var ErrEmptyImage = errors.New("image is empty")
+
+// Placeholder holds the values the app renders while the real cover is loading.
+type Placeholder struct {
+	DominantColor string
+	BlurHash      string
+}
+
+type Analyzer struct {
+	client *resty.Client
+}
+
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{
+		client: httplib.NewClient(),
+	}
+}
+
+// Decode decodes a JPEG, PNG or WebP image.
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// Compute calculates the dominant colour and the BlurHash of the image.
+func Compute(img image.Image) (*Placeholder, error) {
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+
+	return &Placeholder{
+		DominantColor: dominantColor(img),
+		BlurHash:      encodeBlurHash(img, BlurHashComponentsX, BlurHashComponentsY),
+	}, nil
+}
+
+// Analyze downloads the small variant of the image at src and computes its placeholder.
+func (a *Analyzer) Analyze(ctx context.Context, src string) (*Placeholder, error) {
+	if len(src) == 0 {
+		return nil, ErrEmptyImage
+	}
+
+	url := bangumi.SmallVariant(src)
+	if strings.HasPrefix(url, "//") {
+		url = "https:" + url
+	}
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetHeader("User-Agent", bangumi.UserAgentHeader).
+		Get(url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s, status code: %d", url, resp.StatusCode())
+	}
+
+	img, err := Decode(bytes.NewReader(resp.Body()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+
+	return Compute(img)
+}
+
+// AnalyzeSubjects fills in the placeholder of each subject that has an image.
+// Subjects that fail to analyse are left untouched and the errors are joined.
+func (a *Analyzer) AnalyzeSubjects(ctx context.Context, subjects []model.FirestoreSubject) error {
+	targets := make([]target, len(subjects))
+	for i := range subjects {
+		targets[i] = target{
+			image:         subjects[i].Image,
+			dominantColor: &subjects[i].DominantColor,
+			blurHash:      &subjects[i].BlurHash,
+		}
+	}
+
+	return a.analyze(ctx, targets)
+}
+
+// AnalyzeSeasonIndex fills in the placeholder of each season index item that has an image.
+func (a *Analyzer) AnalyzeSeasonIndex(ctx context.Context, items []model.FirestoreSeasonIndexItem) error {
+	targets := make([]target, len(items))
+	for i := range items {
+		targets[i] = target{
+			image:         items[i].Image,
+			dominantColor: &items[i].DominantColor,
+			blurHash:      &items[i].BlurHash,
+		}
+	}
+
+	return a.analyze(ctx, targets)
+}
+
+type target struct {
+	image         string
+	dominantColor *string
+	blurHash      *string
+}
+
+func (a *Analyzer) analyze(ctx context.Context, targets []target) error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, MaxConcurrentDownloads)
+		errs []error
+	)
+
+	for _, t := range targets {
+		if len(t.image) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(t target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			p, err := a.Analyze(ctx, t.image)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+
+			*t.dominantColor = p.DominantColor
+			*t.blurHash = p.BlurHash
+		}(t)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// dominantColor buckets the pixels by their most significant bits and returns the
+// average colour of the most populated bucket as a hex string such as "#aabbcc".
+func dominantColor(img image.Image) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+
+	buckets := make(map[int]*bucket)
+	var best *bucket
+
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/maxSampleSize)
+	stepY := max(1, bounds.Dy()/maxSampleSize)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, alpha := img.At(x, y).RGBA()
+			if alpha < 0x8000 {
+				// ignore mostly transparent pixels
+				continue
+			}
+
+			r8, g8, b8 := int(r>>8), int(g>>8), int(b>>8)
+			shift := 8 - colorBits
+			key := (r8>>shift)<<(2*colorBits) | (g8>>shift)<<colorBits | b8>>shift
+
+			bk, ok := buckets[key]
+			if !ok {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+
+			bk.count++
+			bk.r += r8
+			bk.g += g8
+			bk.b += b8
+
+			if best == nil || bk.count > best.count {
+				best = bk
+			}
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
@@ -0,0 +1,101 @@
+package placeholder
+
+import (
+	"bytes"
+	"context"
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+)
+
+func solidImage(c color.Color, w int, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+var _ = Describe("placeholder unit tests", func() {
+	Describe("Compute", func() {
+		It("returns the colour and the average colour blurhash for a solid image", func() {
+			got, err := Compute(solidImage(color.RGBA{R: 255, A: 255}, 32, 32))
+
+			Expect(err).To(BeNil())
+			Expect(got.DominantColor).To(Equal("#ff0000"))
+			Expect(got.BlurHash).To(HaveLen(28))
+			Expect(got.BlurHash[:1]).To(Equal("L"))
+			Expect(got.BlurHash[2:6]).To(Equal(encode83(0xff0000, 4)))
+		})
+
+		It("picks the most common colour as dominant", func() {
+			img := solidImage(color.RGBA{B: 200, A: 255}, 10, 10)
+			for x := 0; x < 3; x++ {
+				img.Set(x, 0, color.RGBA{G: 255, A: 255})
+			}
+
+			got, err := Compute(img)
+
+			Expect(err).To(BeNil())
+			Expect(got.DominantColor).To(Equal("#0000c8"))
+		})
+
+		It("returns error for an empty image", func() {
+			_, err := Compute(image.NewRGBA(image.Rect(0, 0, 0, 0)))
+
+			Expect(err).To(Equal(ErrEmptyImage))
+		})
+	})
+
+	Describe("AnalyzeSubjects", func() {
+		var analyzer *Analyzer
+
+		BeforeEach(func() {
+			analyzer = NewAnalyzer()
+			httpmock.ActivateNonDefault(analyzer.client.GetClient())
+		})
+
+		It("analyses the small variant of each cover", func() {
+			var buf bytes.Buffer
+			Expect(png.Encode(&buf, solidImage(color.RGBA{G: 255, A: 255}, 8, 8))).To(Succeed())
+
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/s/1.jpg",
+				func(req *http.Request) (*http.Response, error) {
+					return httpmock.NewBytesResponse(200, buf.Bytes()), nil
+				},
+			)
+
+			subjects := []model.FirestoreSubject{
+				{ID: 1, Image: "https://lain.bgm.tv/pic/cover/l/1.jpg"},
+				{ID: 2},
+			}
+
+			err := analyzer.AnalyzeSubjects(context.Background(), subjects)
+
+			Expect(err).To(BeNil())
+			Expect(subjects[0].DominantColor).To(Equal("#00ff00"))
+			Expect(subjects[0].BlurHash).ToNot(BeEmpty())
+			Expect(subjects[1].BlurHash).To(BeEmpty())
+		})
+
+		It("returns error if the image cannot be decoded", func() {
+			httpmock.RegisterResponder("GET", "https://lain.bgm.tv/pic/cover/s/1.jpg", httpmock.NewStringResponder(200, "not an image"))
+
+			subjects := []model.FirestoreSubject{
+				{ID: 1, Image: "https://lain.bgm.tv/pic/cover/l/1.jpg"},
+			}
+
+			err := analyzer.AnalyzeSubjects(context.Background(), subjects)
+
+			Expect(err).ToNot(BeNil())
+			Expect(subjects[0].BlurHash).To(BeEmpty())
+		})
+	})
+})
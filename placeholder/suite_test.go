@@ -0,0 +1,21 @@
+package placeholder
+
+import (
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestPlaceholder(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "placeholder test suite")
+}
+
+var _ = BeforeEach(func() {
+	httpmock.Reset()
+})
+
+var _ = AfterSuite(func() {
+	httpmock.DeactivateAndReset()
+})
@@ -0,0 +1,138 @@
+package query
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	"strings"
+	"time"
+)
+
+// subject is the common view of the subject models a query is evaluated against.
+type subject struct {
+	id      int
+	typ     int
+	names   []string
+	tags    []string
+	score   float64
+	rank    int
+	collect int
+	year    int // 0 when the air date is unknown
+}
+
+// Match reports whether the subject satisfies every term.
+func (q *Query) Match(s model.BangumiSubject) bool {
+	tags := make([]string, len(s.Tags))
+	for i, tag := range s.Tags {
+		tags[i] = tag.Name
+	}
+
+	var year int
+	if airDate, ok := s.AirDate(); ok {
+		year = airDate.Year()
+	}
+
+	return q.match(subject{
+		id:      s.ID,
+		typ:     s.Type,
+		names:   []string{s.Name, s.NameCn},
+		tags:    tags,
+		score:   s.Rating.Score,
+		rank:    s.Rating.Rank,
+		collect: s.Collection.Total(),
+		year:    year,
+	})
+}
+
+// MatchFirestore reports whether the published subject satisfies every term.
+// Tags are read back from the info line and year terms never match as the air date is not published.
+func (q *Query) MatchFirestore(s model.FirestoreSubject) bool {
+	var tags []string
+	for _, tag := range strings.Split(s.Info, "/") {
+		if tag = strings.TrimSpace(tag); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+
+	return q.match(subject{
+		id:      s.ID,
+		typ:     s.Type,
+		names:   []string{s.Name, s.NameCn},
+		tags:    tags,
+		score:   s.Score,
+		rank:    s.Rank,
+		collect: s.Collection,
+	})
+}
+
+func (q *Query) match(s subject) bool {
+	for _, term := range q.Terms {
+		if term.match(s) == term.Negated {
+			return false
+		}
+	}
+	return true
+}
+
+func (t Term) match(s subject) bool {
+	switch t.Field {
+	case FieldName:
+		value := strings.ToLower(t.Value)
+		for _, name := range s.names {
+			if strings.Contains(strings.ToLower(name), value) {
+				return true
+			}
+		}
+		return false
+	case FieldTag:
+		for _, tag := range s.tags {
+			if strings.EqualFold(tag, t.Value) {
+				return true
+			}
+		}
+		return false
+	case FieldID:
+		return t.inRange(float64(s.id))
+	case FieldType:
+		return t.inRange(float64(s.typ))
+	case FieldScore:
+		return t.inRange(s.score)
+	case FieldRank:
+		// unranked subjects have rank 0
+		return s.rank > 0 && t.inRange(float64(s.rank))
+	case FieldCollect:
+		return t.inRange(float64(s.collect))
+	case FieldYear:
+		return s.year > 0 && t.inRange(float64(s.year))
+	}
+	return false
+}
+
+func (t Term) inRange(v float64) bool {
+	if t.Min != nil && (v < *t.Min || (t.MinExclusive && v == *t.Min)) {
+		return false
+	}
+	if t.Max != nil && (v > *t.Max || (t.MaxExclusive && v == *t.Max)) {
+		return false
+	}
+	return true
+}
+
+// yearBounds converts a year term into the air date interval [from, to).
+func (t Term) yearBounds() (from *time.Time, to *time.Time) {
+	if t.Min != nil {
+		y := int(*t.Min)
+		if t.MinExclusive {
+			y++
+		}
+		f := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+		from = &f
+	}
+	if t.Max != nil {
+		y := int(*t.Max)
+		if !t.MaxExclusive {
+			y++
+		}
+		e := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+		to = &e
+	}
+	return from, to
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("query evaluation unit tests", func() {
+	subject := model.BangumiSubject{
+		ID:         1,
+		Type:       2,
+		Name:       "葬送のフリーレン",
+		NameCn:     "葬送的芙莉莲",
+		Date:       "2023-09-29",
+		Tags:       model.BangumiTags{{Name: "奇幻"}, {Name: "百合"}},
+		Rating:     model.BangumiRating{Score: 8.9, Rank: 5},
+		Collection: model.BangumiCollection{Collect: 2000},
+	}
+
+	match := func(expr string) bool {
+		q, err := Parse(expr)
+		Expect(err).To(BeNil())
+		return q.Match(subject)
+	}
+
+	It("matches every term", func() {
+		Expect(match("type:anime score>=7.5 tag:百合 -tag:后宫 year:2023..2024 collect>1000")).To(BeTrue())
+		Expect(match("芙莉莲 rank<=10")).To(BeTrue())
+	})
+
+	It("rejects subjects failing any term", func() {
+		Expect(match("type:book")).To(BeFalse())
+		Expect(match("-tag:奇幻")).To(BeFalse())
+		Expect(match("collect>2000")).To(BeFalse())
+		Expect(match("year:2024..")).To(BeFalse())
+	})
+
+	It("evaluates published subjects using the info line for tags", func() {
+		q, err := Parse("tag:百合 score>8 year:2023")
+		Expect(err).To(BeNil())
+
+		published := subject.ToFirestoreSubject()
+		Expect(q.MatchFirestore(published)).To(BeFalse())
+
+		q, err = Parse("tag:百合 score>8")
+		Expect(err).To(BeNil())
+		Expect(q.MatchFirestore(published)).To(BeTrue())
+	})
+})
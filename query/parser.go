@@ -0,0 +1,306 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Field string
+type Op string
+
+const (
+	FieldName    Field = "name"
+	FieldID      Field = "id"
+	FieldType    Field = "type"
+	FieldTag     Field = "tag"
+	FieldScore   Field = "score"
+	FieldRank    Field = "rank"
+	FieldCollect Field = "collect"
+	FieldYear    Field = "year"
+
+	OpMatch Op = ":"
+	OpEq    Op = "="
+	OpGt    Op = ">"
+	OpGte   Op = ">="
+	OpLt    Op = "<"
+	OpLte   Op = "<="
+
+	RangeSeparator = ".."
+)
+
+// SubjectTypes maps the type names accepted by "type:" to Bangumi subject types.
+var SubjectTypes = map[string]int{
+	"book":  1,
+	"anime": 2,
+	"music": 3,
+	"game":  4,
+	"real":  6,
+}
+
+var numericFields = map[Field]bool{
+	FieldID:      true,
+	FieldType:    true,
+	FieldScore:   true,
+	FieldRank:    true,
+	FieldCollect: true,
+	FieldYear:    true,
+}
+
+// Error is a parse error, Pos is the 0-based rune offset in the expression.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query: position %d: %s", e.Pos, e.Msg)
+}
+
+// Query is a parsed expression, every term must hold for a subject to match.
+type Query struct {
+	Terms []Term
+}
+
+// Term is a single condition such as "score>=7.5" or "-tag:后宫". Bare words, including words such as
+// "Re:Zero" whose prefix before ':' is not a field, become name terms.
+type Term struct {
+	Pos     int
+	Negated bool
+	Field   Field
+	Op      Op
+	Value   string
+
+	// Min and Max bound numeric terms, nil means unbounded. They are exclusive for '>' and '<'.
+	Min          *float64
+	Max          *float64
+	MinExclusive bool
+	MaxExclusive bool
+}
+
+func (t Term) String() string {
+	var sb strings.Builder
+	if t.Negated {
+		sb.WriteString("-")
+	}
+	sb.WriteString(string(t.Field))
+	sb.WriteString(string(t.Op))
+	sb.WriteString(t.Value)
+	return sb.String()
+}
+
+// Parse parses an expression such as `type:anime score>=7.5 tag:百合 -tag:后宫 year:2024..2025 collect>1000`.
+func Parse(expr string) (*Query, error) {
+	p := &parser{input: []rune(expr)}
+
+	var q Query
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			break
+		}
+
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		q.Terms = append(q.Terms, *term)
+	}
+
+	return &q, nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(pos int, format string, args ...interface{}) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) term() (*Term, error) {
+	term := &Term{Pos: p.pos}
+
+	if p.peek() == '-' {
+		term.Negated = true
+		p.pos++
+		if p.eof() || unicode.IsSpace(p.peek()) {
+			return nil, p.errorf(term.Pos, "dangling negation")
+		}
+	}
+
+	// a field name is followed directly by an operator, anything else is a bare word
+	start := p.pos
+	for !p.eof() && (unicode.IsLetter(p.peek()) && p.peek() < unicode.MaxASCII) {
+		p.pos++
+	}
+	name := string(p.input[start:p.pos])
+
+	// a colon after an unknown field name is part of a title such as "Re:Zero"
+	op := p.op()
+	if op == "" || len(name) == 0 || (op == OpMatch && !knownField(Field(strings.ToLower(name)))) {
+		p.pos = start
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		term.Field = FieldName
+		term.Op = OpMatch
+		term.Value = value
+		return term, nil
+	}
+
+	term.Field = Field(strings.ToLower(name))
+	term.Op = op
+
+	valuePos := p.pos
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, p.errorf(valuePos, "missing value for %s", term.Field)
+	}
+	term.Value = value
+
+	if err := p.bind(term, start, valuePos); err != nil {
+		return nil, err
+	}
+
+	return term, nil
+}
+
+func knownField(f Field) bool {
+	return f == FieldName || f == FieldTag || numericFields[f]
+}
+
+func (p *parser) op() Op {
+	for _, op := range []Op{OpGte, OpLte, OpGt, OpLt, OpEq, OpMatch} {
+		if strings.HasPrefix(string(p.input[p.pos:]), string(op)) {
+			p.pos += len([]rune(op))
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *parser) value() (string, error) {
+	if p.peek() == '"' {
+		start := p.pos
+		p.pos++
+		var sb strings.Builder
+		for !p.eof() && p.peek() != '"' {
+			sb.WriteRune(p.peek())
+			p.pos++
+		}
+		if p.eof() {
+			return "", p.errorf(start, "unterminated quoted string")
+		}
+		p.pos++
+		return sb.String(), nil
+	}
+
+	start := p.pos
+	for !p.eof() && !unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos]), nil
+}
+
+// bind validates the field and operator and resolves numeric bounds.
+func (p *parser) bind(term *Term, fieldPos int, valuePos int) error {
+	switch term.Field {
+	case FieldName, FieldTag:
+		if term.Op != OpMatch && term.Op != OpEq {
+			return p.errorf(fieldPos, "%s only supports ':'", term.Field)
+		}
+		return nil
+	case FieldType:
+		if term.Op != OpMatch && term.Op != OpEq {
+			return p.errorf(fieldPos, "type only supports ':'")
+		}
+		t, ok := SubjectTypes[strings.ToLower(term.Value)]
+		if !ok {
+			n, err := strconv.Atoi(term.Value)
+			if err != nil {
+				return p.errorf(valuePos, "unknown subject type %q", term.Value)
+			}
+			t = n
+		}
+		v := float64(t)
+		term.Min, term.Max = &v, &v
+		return nil
+	}
+
+	if !numericFields[term.Field] {
+		return p.errorf(fieldPos, "unknown field %q", term.Field)
+	}
+
+	if lo, hi, ok := strings.Cut(term.Value, RangeSeparator); ok {
+		if term.Op != OpMatch && term.Op != OpEq {
+			return p.errorf(valuePos, "ranges only support ':'")
+		}
+
+		if len(lo) > 0 {
+			v, err := strconv.ParseFloat(lo, 64)
+			if err != nil {
+				return p.errorf(valuePos, "invalid number %q", lo)
+			}
+			term.Min = &v
+		}
+
+		if len(hi) > 0 {
+			v, err := strconv.ParseFloat(hi, 64)
+			if err != nil {
+				return p.errorf(valuePos+len([]rune(lo))+len(RangeSeparator), "invalid number %q", hi)
+			}
+			term.Max = &v
+		}
+
+		if term.Min == nil && term.Max == nil {
+			return p.errorf(valuePos, "empty range")
+		}
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(term.Value, 64)
+	if err != nil {
+		return p.errorf(valuePos, "invalid number %q", term.Value)
+	}
+
+	switch term.Op {
+	case OpMatch, OpEq:
+		term.Min, term.Max = &v, &v
+	case OpGt:
+		term.Min, term.MinExclusive = &v, true
+	case OpGte:
+		term.Min = &v
+	case OpLt:
+		term.Max, term.MaxExclusive = &v, true
+	case OpLte:
+		term.Max = &v
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("query parser unit tests", func() {
+	It("parses fields, operators, negations and ranges", func() {
+		q, err := Parse(`type:anime score>=7.5 tag:百合 -tag:后宫 year:2024..2025 collect>1000`)
+
+		Expect(err).To(BeNil())
+		Expect(q.Terms).To(HaveLen(6))
+
+		Expect(q.Terms[0].Field).To(Equal(FieldType))
+		Expect(*q.Terms[0].Min).To(Equal(2.0))
+
+		Expect(q.Terms[1].Op).To(Equal(OpGte))
+		Expect(*q.Terms[1].Min).To(Equal(7.5))
+		Expect(q.Terms[1].Max).To(BeNil())
+
+		Expect(q.Terms[3].Negated).To(BeTrue())
+		Expect(q.Terms[3].Value).To(Equal("后宫"))
+		Expect(q.Terms[3].Pos).To(Equal(29))
+
+		Expect(*q.Terms[4].Min).To(Equal(2024.0))
+		Expect(*q.Terms[4].Max).To(Equal(2025.0))
+
+		Expect(q.Terms[5].MinExclusive).To(BeTrue())
+	})
+
+	It("parses bare and quoted words as name terms", func() {
+		q, err := Parse(`葬送 name:"Sousou no"`)
+
+		Expect(err).To(BeNil())
+		Expect(q.Terms).To(HaveLen(2))
+		Expect(q.Terms[0].Field).To(Equal(FieldName))
+		Expect(q.Terms[0].Value).To(Equal("葬送"))
+		Expect(q.Terms[1].Value).To(Equal("Sousou no"))
+	})
+
+	It("parses words with a colon after an unknown field as name terms", func() {
+		q, err := Parse(`Re:Zero -Steins:Gate type:anime`)
+
+		Expect(err).To(BeNil())
+		Expect(q.Terms).To(HaveLen(3))
+		Expect(q.Terms[0].Field).To(Equal(FieldName))
+		Expect(q.Terms[0].Value).To(Equal("Re:Zero"))
+		Expect(q.Terms[1].Negated).To(BeTrue())
+		Expect(q.Terms[1].Value).To(Equal("Steins:Gate"))
+		Expect(q.Terms[2].Field).To(Equal(FieldType))
+	})
+
+	DescribeTable("reports the position of parse errors",
+		func(expr string, pos int) {
+			_, err := Parse(expr)
+
+			Expect(err).ToNot(BeNil())
+			Expect(err.(*Error).Pos).To(Equal(pos))
+		},
+		Entry("unknown field", "type:anime foo>1", 11),
+		Entry("invalid number", "score>=abc", 7),
+		Entry("invalid range upper bound", "year:2024..x", 11),
+		Entry("unknown type", "type:movie", 5),
+		Entry("unterminated quote", `tag:"百合`, 4),
+		Entry("missing value", "score>= tag:x", 7),
+		Entry("comparison on tag", "tag>1", 0),
+		Entry("dangling negation", "type:anime - tag:x", 11),
+	)
+})
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchRequest compiles the query into a POST /v0/search/subjects request body.
+// Terms the API cannot express, such as negations and collection counts, are returned
+// so callers can apply them with Match on the search results.
+func (q *Query) SearchRequest() (model.BangumiSearchRequest, []Term) {
+	var (
+		req         model.BangumiSearchRequest
+		keywords    []string
+		unsupported []Term
+	)
+
+	for _, term := range q.Terms {
+		if term.Negated {
+			unsupported = append(unsupported, term)
+			continue
+		}
+
+		switch term.Field {
+		case FieldName:
+			keywords = append(keywords, term.Value)
+		case FieldTag:
+			req.Filter.Tag = append(req.Filter.Tag, term.Value)
+		case FieldType:
+			// types are OR'ed by the API while terms are AND'ed, so only one type can be compiled
+			if len(req.Filter.Type) > 0 {
+				unsupported = append(unsupported, term)
+				continue
+			}
+			req.Filter.Type = []int{int(*term.Min)}
+		case FieldScore:
+			req.Filter.Rating = append(req.Filter.Rating, term.bounds()...)
+		case FieldRank:
+			req.Filter.Rank = append(req.Filter.Rank, term.bounds()...)
+		case FieldYear:
+			from, to := term.yearBounds()
+			if from != nil {
+				req.Filter.AirDate = append(req.Filter.AirDate, ">="+from.Format(time.DateOnly))
+			}
+			if to != nil {
+				req.Filter.AirDate = append(req.Filter.AirDate, "<"+to.Format(time.DateOnly))
+			}
+		default:
+			unsupported = append(unsupported, term)
+		}
+	}
+
+	req.Keyword = strings.Join(keywords, " ")
+
+	return req, unsupported
+}
+
+// bounds renders the term as API comparison strings such as ">=7.5".
+func (t Term) bounds() []string {
+	if t.Min != nil && t.Max != nil && *t.Min == *t.Max && !t.MinExclusive && !t.MaxExclusive {
+		return []string{"=" + formatNumber(*t.Min)}
+	}
+
+	var res []string
+	if t.Min != nil {
+		op := ">="
+		if t.MinExclusive {
+			op = ">"
+		}
+		res = append(res, op+formatNumber(*t.Min))
+	}
+	if t.Max != nil {
+		op := "<="
+		if t.MaxExclusive {
+			op = "<"
+		}
+		res = append(res, op+formatNumber(*t.Max))
+	}
+	return res
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
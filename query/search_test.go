@@ -0,0 +1,36 @@
+package query
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("query search compilation unit tests", func() {
+	It("compiles supported terms into the search filter", func() {
+		q, err := Parse("葬送 type:anime score>=7.5 tag:百合 year:2024..2025 rank<100")
+		Expect(err).To(BeNil())
+
+		req, unsupported := q.SearchRequest()
+
+		Expect(unsupported).To(BeEmpty())
+		Expect(req.Keyword).To(Equal("葬送"))
+		Expect(req.Filter.Type).To(Equal([]int{2}))
+		Expect(req.Filter.Tag).To(Equal([]string{"百合"}))
+		Expect(req.Filter.Rating).To(Equal([]string{">=7.5"}))
+		Expect(req.Filter.Rank).To(Equal([]string{"<100"}))
+		Expect(req.Filter.AirDate).To(Equal([]string{">=2024-01-01", "<2026-01-01"}))
+	})
+
+	It("returns the terms the API cannot express", func() {
+		q, err := Parse("tag:百合 -tag:后宫 collect>1000 type:anime type:book")
+		Expect(err).To(BeNil())
+
+		req, unsupported := q.SearchRequest()
+
+		Expect(req.Filter.Tag).To(Equal([]string{"百合"}))
+		Expect(unsupported).To(HaveLen(3))
+		Expect(unsupported[0].String()).To(Equal("-tag:后宫"))
+		Expect(unsupported[1].String()).To(Equal("collect>1000"))
+		Expect(unsupported[2].String()).To(Equal("type:book"))
+	})
+})
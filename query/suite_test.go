@@ -0,0 +1,12 @@
+package query
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestQuery(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "query test suite")
+}
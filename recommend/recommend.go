@@ -0,0 +1,189 @@
+package recommend
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	"github.com/bangumilite/bangumilite-component/tag"
+	"math"
+	"sort"
+)
+
+type Config struct {
+	// Normalizer merges tag variants, defaults to one using tag.DefaultSynonyms.
+	Normalizer *tag.Normalizer
+	// MaxTags is the number of most voted tags considered for each subject.
+	MaxTags int
+	// MetaTags are multiplied by MetaTagWeight in addition to the year and format noise tags.
+	MetaTags      []string
+	MetaTagWeight float64
+	// MinSimilarity drops pairs scoring below it.
+	MinSimilarity float64
+	// Limit is the number of related subjects kept per subject.
+	Limit int
+}
+
+var DefaultConfig = Config{
+	MaxTags:       15,
+	MetaTagWeight: 0.1,
+	MinSimilarity: 0.05,
+	Limit:         10,
+}
+
+// Related is a subject similar to another one, with a similarity in [0, 1].
+type Related struct {
+	SubjectID  int
+	Similarity float64
+}
+
+type Recommender struct {
+	cfg        Config
+	normalizer *tag.Normalizer
+	metaTags   map[string]bool
+}
+
+func New(cfg Config) *Recommender {
+	normalizer := cfg.Normalizer
+	if normalizer == nil {
+		normalizer = tag.NewNormalizer(tag.DefaultSynonyms)
+	}
+
+	metaTags := make(map[string]bool, len(cfg.MetaTags))
+	for _, name := range cfg.MetaTags {
+		metaTags[normalizer.Key(name)] = true
+	}
+
+	return &Recommender{
+		cfg:        cfg,
+		normalizer: normalizer,
+		metaTags:   metaTags,
+	}
+}
+
+// Similar computes, for every subject of the corpus, the most similar other subjects, best first.
+// Subjects are compared by the weighted Jaccard similarity of their TF-IDF tag vectors.
+func (r *Recommender) Similar(corpus []model.BangumiSubject) map[int][]Related {
+	vectors := make([]map[string]float64, len(corpus))
+	df := make(map[string]int)
+	for i, subject := range corpus {
+		vectors[i] = r.termFrequencies(subject.Tags)
+		for name := range vectors[i] {
+			df[name]++
+		}
+	}
+
+	n := float64(len(corpus))
+	postings := make(map[string][]int)
+	for i, vector := range vectors {
+		for name, tf := range vector {
+			// smoothed idf, a tag present on every subject still keeps a small weight
+			vector[name] = tf * math.Log(1+n/float64(df[name]))
+			postings[name] = append(postings[name], i)
+		}
+	}
+
+	res := make(map[int][]Related, len(corpus))
+	for i, subject := range corpus {
+		candidates := make(map[int]bool)
+		for name := range vectors[i] {
+			for _, j := range postings[name] {
+				if j != i && corpus[j].ID != subject.ID {
+					candidates[j] = true
+				}
+			}
+		}
+
+		var related []Related
+		for j := range candidates {
+			similarity := weightedJaccard(vectors[i], vectors[j])
+			if similarity < r.cfg.MinSimilarity {
+				continue
+			}
+			related = append(related, Related{SubjectID: corpus[j].ID, Similarity: similarity})
+		}
+
+		sort.Slice(related, func(a, b int) bool {
+			if related[a].Similarity != related[b].Similarity {
+				return related[a].Similarity > related[b].Similarity
+			}
+			return related[a].SubjectID < related[b].SubjectID
+		})
+
+		if r.cfg.Limit > 0 && len(related) > r.cfg.Limit {
+			related = related[:r.cfg.Limit]
+		}
+
+		res[subject.ID] = related
+	}
+
+	return res
+}
+
+// RelatedSubjects is Similar resolved to the subjects published in each subject's related document.
+func (r *Recommender) RelatedSubjects(corpus []model.BangumiSubject) map[int][]model.FirestoreSubject {
+	byID := make(map[int]model.BangumiSubject, len(corpus))
+	for _, subject := range corpus {
+		byID[subject.ID] = subject
+	}
+
+	res := make(map[int][]model.FirestoreSubject)
+	for id, related := range r.Similar(corpus) {
+		subjects := make([]model.FirestoreSubject, 0, len(related))
+		for _, rel := range related {
+			subjects = append(subjects, byID[rel.SubjectID].ToFirestoreSubject())
+		}
+		res[id] = subjects
+	}
+
+	return res
+}
+
+// termFrequencies weights each merged tag by its vote count relative to the most voted tag.
+// Without counts, tags are weighted by position as Bangumi lists the most voted tags first.
+func (r *Recommender) termFrequencies(tags model.BangumiTags) map[string]float64 {
+	merged := r.normalizer.Merge(tags)
+
+	maxCount := 0
+	for _, t := range merged {
+		maxCount = max(maxCount, t.Count)
+	}
+
+	tf := make(map[string]float64)
+	for i, t := range merged {
+		if r.cfg.MaxTags > 0 && i >= r.cfg.MaxTags {
+			break
+		}
+
+		weight := 1 / math.Sqrt(float64(i+1))
+		if maxCount > 0 {
+			weight = math.Log1p(float64(t.Count)) / math.Log1p(float64(maxCount))
+		}
+
+		key := r.normalizer.Key(t.Name)
+		if r.normalizer.IsNoise(t.Name) || r.metaTags[key] {
+			weight *= r.cfg.MetaTagWeight
+		}
+
+		tf[key] = weight
+	}
+
+	return tf
+}
+
+func weightedJaccard(a map[string]float64, b map[string]float64) float64 {
+	var minSum, maxSum float64
+	for name, wa := range a {
+		wb := b[name]
+		minSum += math.Min(wa, wb)
+		maxSum += math.Max(wa, wb)
+	}
+	for name, wb := range b {
+		if _, ok := a[name]; !ok {
+			maxSum += wb
+		}
+	}
+
+	if maxSum == 0 {
+		return 0
+	}
+
+	return minSum / maxSum
+}
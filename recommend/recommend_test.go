@@ -0,0 +1,59 @@
+package recommend
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func tags(names ...string) model.BangumiTags {
+	res := make(model.BangumiTags, len(names))
+	for i, name := range names {
+		res[i] = model.BangumiTag{Name: name}
+	}
+	return res
+}
+
+var _ = Describe("recommend unit tests", func() {
+	corpus := []model.BangumiSubject{
+		{ID: 1, Tags: tags("百合", "校园", "日常", "TV", "2024")},
+		{ID: 2, Tags: tags("百合", "校园", "音乐", "TV", "2023")},
+		{ID: 3, Tags: tags("机战", "科幻", "TV", "2024")},
+		{ID: 4, Tags: tags("科幻", "机战", "战争", "TV", "2024")},
+		{ID: 5, Tags: tags("ＴＶ", "2024")},
+	}
+
+	It("ranks subjects sharing content tags first", func() {
+		got := New(DefaultConfig).Similar(corpus)
+
+		Expect(got[1]).ToNot(BeEmpty())
+		Expect(got[1][0].SubjectID).To(Equal(2))
+		Expect(got[3][0].SubjectID).To(Equal(4))
+	})
+
+	It("does not relate subjects sharing only meta tags", func() {
+		got := New(DefaultConfig).Similar(corpus)
+
+		Expect(got[5]).To(BeEmpty())
+		for _, related := range got[1] {
+			Expect(related.SubjectID).ToNot(Equal(3))
+		}
+	})
+
+	It("applies the limit", func() {
+		cfg := DefaultConfig
+		cfg.Limit = 1
+		cfg.MinSimilarity = 0
+
+		got := New(cfg).Similar(corpus)
+
+		Expect(got[1]).To(HaveLen(1))
+	})
+
+	It("resolves related subjects for publishing", func() {
+		got := New(DefaultConfig).RelatedSubjects(corpus)
+
+		Expect(got[4]).ToNot(BeEmpty())
+		Expect(got[4][0].ID).To(Equal(3))
+	})
+})
@@ -0,0 +1,12 @@
+package recommend
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestRecommend(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "recommend test suite")
+}
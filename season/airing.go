@@ -0,0 +1,124 @@
+package season
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	"time"
+)
+
+// EpisodeInterval is the broadcast cadence assumed when only an episode count is known.
+const EpisodeInterval = 7 * 24 * time.Hour
+
+type AiringStatus string
+
+const (
+	StatusNew        AiringStatus = "new"
+	StatusContinuing AiringStatus = "continuing"
+)
+
+// Airing is one season a show airs in.
+type Airing struct {
+	Season Season
+	Status AiringStatus
+}
+
+func (a Airing) Continuing() bool {
+	return a.Status == StatusContinuing
+}
+
+// Airings returns every season a show premiering at start with the given number of weekly episodes airs in.
+// The premiere season is classified with the grace window and is marked new, the following ones are continuing.
+// An unknown episode count (0) yields the premiere season only.
+func (c Classifier) Airings(start time.Time, episodes int) []Airing {
+	last := start
+	if episodes > 1 {
+		last = start.Add(time.Duration(episodes-1) * EpisodeInterval)
+	}
+
+	return c.airings(start, last)
+}
+
+// EpisodeAirings returns every season the main episodes air in, based on their air dates.
+// It reports false if none of the episodes has an air date.
+func (c Classifier) EpisodeAirings(episodes []model.BangumiEpisode) ([]Airing, bool) {
+	var first, last time.Time
+	found := false
+
+	for _, e := range episodes {
+		if e.Type != model.EpisodeTypeMain {
+			continue
+		}
+
+		airDate, ok := e.AirDate()
+		if !ok {
+			continue
+		}
+
+		local := c.date(airDate)
+		if !found || local.Before(first) {
+			first = local
+		}
+		if !found || local.After(last) {
+			last = local
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, false
+	}
+
+	return c.airings(first, last), true
+}
+
+// SubjectAirings returns every season the subject airs in from its air date and episode count.
+// It reports false if the air date is missing.
+func (c Classifier) SubjectAirings(subject model.BangumiSubject) ([]Airing, bool) {
+	airDate, ok := subject.AirDate()
+	if !ok {
+		return nil, false
+	}
+
+	return c.Airings(c.date(airDate), subject.Eps), true
+}
+
+// SubjectAiringIn reports whether the subject airs in the season and whether it is new or continuing there.
+func (c Classifier) SubjectAiringIn(subject model.BangumiSubject, s Season) (AiringStatus, bool) {
+	airings, ok := c.SubjectAirings(subject)
+	if !ok {
+		return "", false
+	}
+
+	for _, a := range airings {
+		if a.Season.Compare(s) == 0 {
+			return a.Status, true
+		}
+	}
+
+	return "", false
+}
+
+func (c Classifier) airings(first time.Time, last time.Time) []Airing {
+	premiere := c.Classify(first)
+
+	// The last episode is not moved by the grace window, a finale in late March stays in winter.
+	end := premiere
+	if local := last.In(c.location); premiere.Before(of(local.Year(), local.Month())) {
+		end = of(local.Year(), local.Month())
+	}
+
+	var airings []Airing
+	for _, s := range Range(premiere, end) {
+		status := StatusContinuing
+		if s.Compare(premiere) == 0 {
+			status = StatusNew
+		}
+		airings = append(airings, Airing{Season: s, Status: status})
+	}
+
+	return airings
+}
+
+// date interprets a calendar date in the classifier's zone.
+func (c Classifier) date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.location)
+}
@@ -0,0 +1,87 @@
+package season
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+func airingIDs(airings []Airing) []string {
+	var ids []string
+	for _, a := range airings {
+		ids = append(ids, a.Season.ID()+":"+string(a.Status))
+	}
+	return ids
+}
+
+var _ = Describe("season airing unit tests", func() {
+	Describe("Airings", func() {
+		It("returns the premiere season only for a single cour", func() {
+			start := time.Date(2025, 4, 5, 0, 0, 0, 0, DefaultLocation)
+
+			Expect(airingIDs(DefaultClassifier.Airings(start, 12))).To(Equal([]string{"202504:new"}))
+		})
+
+		It("marks following seasons of a split cour as continuing", func() {
+			start := time.Date(2025, 4, 5, 0, 0, 0, 0, DefaultLocation)
+
+			Expect(airingIDs(DefaultClassifier.Airings(start, 24))).To(Equal([]string{"202504:new", "202507:continuing"}))
+		})
+
+		It("keeps a late March finale in its season", func() {
+			start := time.Date(2025, 1, 5, 0, 0, 0, 0, DefaultLocation)
+
+			Expect(airingIDs(DefaultClassifier.Airings(start, 13))).To(Equal([]string{"202501:new"}))
+		})
+
+		It("returns the premiere season for unknown episode counts", func() {
+			start := time.Date(2025, 3, 28, 0, 0, 0, 0, DefaultLocation)
+
+			Expect(airingIDs(DefaultClassifier.Airings(start, 0))).To(Equal([]string{"202504:new"}))
+		})
+	})
+
+	Describe("EpisodeAirings", func() {
+		It("uses the air dates of the main episodes", func() {
+			episodes := []model.BangumiEpisode{
+				{Type: model.EpisodeTypeMain, Airdate: "2024-10-05"},
+				{Type: model.EpisodeTypeMain, Airdate: ""},
+				{Type: 1, Airdate: "2025-08-01"},
+				{Type: model.EpisodeTypeMain, Airdate: "2025-03-22"},
+			}
+
+			got, ok := DefaultClassifier.EpisodeAirings(episodes)
+
+			Expect(ok).To(BeTrue())
+			Expect(airingIDs(got)).To(Equal([]string{"202410:new", "202501:continuing"}))
+			Expect(got[1].Continuing()).To(BeTrue())
+		})
+
+		It("reports false without air dates", func() {
+			_, ok := DefaultClassifier.EpisodeAirings([]model.BangumiEpisode{{Type: model.EpisodeTypeMain}})
+
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("SubjectAiringIn", func() {
+		subject := model.BangumiSubject{Date: "2024-10-02", Eps: 25}
+
+		It("reports the status of the subject in the season", func() {
+			status, ok := DefaultClassifier.SubjectAiringIn(subject, autumn(2024))
+			Expect(ok).To(BeTrue())
+			Expect(status).To(Equal(StatusNew))
+
+			status, ok = DefaultClassifier.SubjectAiringIn(subject, winter(2025))
+			Expect(ok).To(BeTrue())
+			Expect(status).To(Equal(StatusContinuing))
+		})
+
+		It("reports false outside the airing seasons", func() {
+			_, ok := DefaultClassifier.SubjectAiringIn(subject, spring(2025))
+
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
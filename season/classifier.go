@@ -0,0 +1,103 @@
+package season
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	"time"
+)
+
+const (
+	DefaultLocationName = "Asia/Tokyo"
+
+	// DefaultGrace counts shows premiering in the last two weeks of a season as part of the next one,
+	// e.g. a show starting on 25 March is a spring show.
+	DefaultGrace = 14 * 24 * time.Hour
+)
+
+// DefaultLocation is the zone broadcast schedules are published in. Japan has no daylight saving time,
+// so a fixed offset is a faithful fallback when the tz database is not available.
+var DefaultLocation = func() *time.Location {
+	loc, err := time.LoadLocation(DefaultLocationName)
+	if err != nil {
+		return time.FixedZone("JST", 9*60*60)
+	}
+	return loc
+}()
+
+// DefaultClassifier classifies in DefaultLocation with DefaultGrace.
+var DefaultClassifier = NewClassifier()
+
+// Classifier assigns instants and air dates to seasons in a given zone.
+type Classifier struct {
+	location *time.Location
+	grace    time.Duration
+}
+
+type ClassifierOption func(c *Classifier)
+
+func WithLocation(loc *time.Location) ClassifierOption {
+	return func(c *Classifier) {
+		if loc != nil {
+			c.location = loc
+		}
+	}
+}
+
+// WithGrace sets how long before a season starts a premiere already counts for it, 0 disables the grace window.
+func WithGrace(grace time.Duration) ClassifierOption {
+	return func(c *Classifier) {
+		c.grace = max(grace, 0)
+	}
+}
+
+func NewClassifier(opts ...ClassifierOption) Classifier {
+	c := Classifier{
+		location: DefaultLocation,
+		grace:    DefaultGrace,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// Classify returns the season of the instant in the classifier's zone, moving premieres within the
+// grace window before the next season into it.
+func (c Classifier) Classify(t time.Time) Season {
+	local := t.In(c.location)
+	s := of(local.Year(), local.Month())
+
+	if !local.Add(c.grace).Before(c.End(s)) {
+		return s.Next()
+	}
+
+	return s
+}
+
+// Start returns the first instant of the season in the classifier's zone.
+func (c Classifier) Start(s Season) time.Time {
+	return s.start(c.location)
+}
+
+// End returns the first instant after the season in the classifier's zone, i.e. the start of the next season.
+func (c Classifier) End(s Season) time.Time {
+	return c.Start(s.Next())
+}
+
+// ClassifySubject returns the season a subject premiered in, based on its air date.
+// The air date is a calendar date in the classifier's zone. It reports false if the date is missing.
+func (c Classifier) ClassifySubject(subject model.BangumiSubject) (Season, bool) {
+	airDate, ok := subject.AirDate()
+	if !ok {
+		return Season{}, false
+	}
+
+	local := time.Date(airDate.Year(), airDate.Month(), airDate.Day(), 0, 0, 0, 0, c.location)
+	return c.Classify(local), true
+}
+
+// OfSubject classifies the subject with the DefaultClassifier.
+func OfSubject(subject model.BangumiSubject) (Season, bool) {
+	return DefaultClassifier.ClassifySubject(subject)
+}
@@ -0,0 +1,70 @@
+package season
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("season classifier unit tests", func() {
+	Describe("Classify", func() {
+		It("classifies in the configured zone", func() {
+			t := time.Date(2025, 3, 31, 16, 0, 0, 0, time.UTC)
+
+			Expect(NewClassifier(WithGrace(0)).Classify(t).ID()).To(Equal("202504"))
+			Expect(NewClassifier(WithGrace(0), WithLocation(time.UTC)).Classify(t).ID()).To(Equal("202501"))
+		})
+
+		It("counts late premieres in the next season within the grace window", func() {
+			t := time.Date(2025, 3, 25, 0, 0, 0, 0, DefaultLocation)
+
+			Expect(DefaultClassifier.Classify(t).ID()).To(Equal("202504"))
+			Expect(NewClassifier(WithGrace(0)).Classify(t).ID()).To(Equal("202501"))
+		})
+
+		It("keeps premieres before the grace window in their season", func() {
+			t := time.Date(2025, 3, 10, 0, 0, 0, 0, DefaultLocation)
+
+			Expect(DefaultClassifier.Classify(t).ID()).To(Equal("202501"))
+		})
+
+		It("rolls the grace window over the year", func() {
+			t := time.Date(2024, 12, 28, 0, 0, 0, 0, DefaultLocation)
+
+			Expect(DefaultClassifier.Classify(t).ID()).To(Equal("202501"))
+		})
+	})
+
+	Describe("Start and End", func() {
+		It("returns the bounds in the configured zone", func() {
+			pst := time.FixedZone("PST", -8*60*60)
+			c := NewClassifier(WithGrace(0), WithLocation(pst))
+			t := time.Date(2025, 3, 31, 20, 0, 0, 0, pst)
+
+			s := c.Classify(t)
+			Expect(s.ID()).To(Equal("202501"))
+			Expect(c.Start(s)).To(Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, pst)))
+			Expect(c.End(s)).To(Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, pst)))
+			Expect(t.Before(c.End(s))).To(BeTrue())
+
+			Expect(NewClassifier(WithGrace(0)).Classify(t).ID()).To(Equal("202504"))
+			Expect(t.Before(s.End())).To(BeFalse())
+		})
+	})
+
+	Describe("ClassifySubject", func() {
+		It("classifies by air date", func() {
+			got, ok := OfSubject(model.BangumiSubject{Date: "2025-03-29"})
+
+			Expect(ok).To(BeTrue())
+			Expect(got.ID()).To(Equal("202504"))
+		})
+
+		It("reports false without air date", func() {
+			_, ok := OfSubject(model.BangumiSubject{})
+
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package season
+
+import (
+	"context"
+	"fmt"
+	"github.com/bangumilite/bangumilite-component/model"
+	"sort"
+	"time"
+)
+
+// SubjectSource returns the subjects airing in a season.
+type SubjectSource func(ctx context.Context, s Season) ([]model.BangumiSubject, error)
+
+// IndexBuilder assembles the season index published by fs.UpdateSeasonIndex.
+type IndexBuilder struct {
+	source SubjectSource
+}
+
+func NewIndexBuilder(source SubjectSource) *IndexBuilder {
+	return &IndexBuilder{
+		source: source,
+	}
+}
+
+// Build creates an index item for every season between from and to, both inclusive.
+// Each season is represented by the cover of its most collected subject, seasons without
+// any subject image are left out.
+func (b *IndexBuilder) Build(ctx context.Context, from time.Time, to time.Time) ([]model.FirestoreSeasonIndexItem, error) {
+	var items []model.FirestoreSeasonIndexItem
+
+	for _, s := range Range(New(from), New(to)) {
+		subjects, err := b.source(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subjects of season %s: %w", s.ID(), err)
+		}
+
+		image := representativeImage(subjects)
+		if len(image) == 0 {
+			continue
+		}
+
+		items = append(items, model.FirestoreSeasonIndexItem{
+			ID:     s.ID(),
+			Image:  image,
+			Source: image,
+		})
+	}
+
+	return items, nil
+}
+
+// MergeIndex merges freshly built items into the existing index, newest season first.
+// Seasons missing from items are kept as they are, and an existing item whose source image did not
+// change is kept so that its mirrored image and placeholder survive. Items are compared by their
+// Source, or by their Image when they have none.
+func MergeIndex(existing []model.FirestoreSeasonIndexItem, items []model.FirestoreSeasonIndexItem) []model.FirestoreSeasonIndexItem {
+	byID := make(map[string]model.FirestoreSeasonIndexItem, len(existing)+len(items))
+	for _, item := range existing {
+		byID[item.ID] = item
+	}
+
+	for _, item := range items {
+		if old, ok := byID[item.ID]; ok && sourceImage(old) == sourceImage(item) {
+			continue
+		}
+		byID[item.ID] = item
+	}
+
+	merged := make([]model.FirestoreSeasonIndexItem, 0, len(byID))
+	for _, item := range byID {
+		merged = append(merged, item)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ID > merged[j].ID
+	})
+
+	return merged
+}
+
+func sourceImage(item model.FirestoreSeasonIndexItem) string {
+	if len(item.Source) > 0 {
+		return item.Source
+	}
+
+	return item.Image
+}
+
+func representativeImage(subjects []model.BangumiSubject) string {
+	var best *model.BangumiSubject
+	for i := range subjects {
+		subject := &subjects[i]
+		if len(subject.Images.Large) == 0 {
+			continue
+		}
+
+		if best == nil || subject.Collection.Total() > best.Collection.Total() {
+			best = subject
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.Images.Large
+}
@@ -0,0 +1,100 @@
+package season
+
+import (
+	"context"
+	"errors"
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("season index unit tests", func() {
+	subjects := map[string][]model.BangumiSubject{
+		"202410": {
+			{ID: 1, Images: model.BangumiImages{Large: "autumn-1.jpg"}, Collection: model.BangumiCollection{Doing: 10}},
+			{ID: 2, Images: model.BangumiImages{Large: "autumn-2.jpg"}, Collection: model.BangumiCollection{Doing: 100}},
+		},
+		"202501": {
+			{ID: 3, Collection: model.BangumiCollection{Doing: 500}},
+			{ID: 4, Images: model.BangumiImages{Large: "winter-4.jpg"}, Collection: model.BangumiCollection{Wish: 50}},
+		},
+		"202504": {
+			{ID: 5, Images: model.BangumiImages{Large: "spring-5.jpg"}},
+		},
+	}
+
+	source := func(ctx context.Context, s Season) ([]model.BangumiSubject, error) {
+		return subjects[s.ID()], nil
+	}
+
+	Describe("Build", func() {
+		It("picks the most collected cover of every season in range", func() {
+			from := time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC)
+			to := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+			got, err := NewIndexBuilder(source).Build(context.Background(), from, to)
+
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal([]model.FirestoreSeasonIndexItem{
+				{ID: "202410", Image: "autumn-2.jpg", Source: "autumn-2.jpg"},
+				{ID: "202501", Image: "winter-4.jpg", Source: "winter-4.jpg"},
+				{ID: "202504", Image: "spring-5.jpg", Source: "spring-5.jpg"},
+			}))
+		})
+
+		It("returns error if the source fails", func() {
+			failing := func(ctx context.Context, s Season) ([]model.BangumiSubject, error) {
+				return nil, errors.New("failed")
+			}
+
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			got, err := NewIndexBuilder(failing).Build(context.Background(), now, now)
+
+			Expect(err).ToNot(BeNil())
+			Expect(got).To(BeNil())
+		})
+	})
+
+	Describe("MergeIndex", func() {
+		It("keeps historical entries and refreshes changed ones", func() {
+			existing := []model.FirestoreSeasonIndexItem{
+				{ID: "202001", Image: "old.jpg"},
+				{ID: "202410", Image: "autumn-2.jpg", BlurHash: "LKO2?U%2Tw=w"},
+				{ID: "202501", Image: "stale.jpg", BlurHash: "LEHV6nWB2yk8"},
+			}
+			items := []model.FirestoreSeasonIndexItem{
+				{ID: "202410", Image: "autumn-2.jpg"},
+				{ID: "202501", Image: "winter-4.jpg"},
+				{ID: "202504", Image: "spring-5.jpg"},
+			}
+
+			got := MergeIndex(existing, items)
+
+			Expect(got).To(Equal([]model.FirestoreSeasonIndexItem{
+				{ID: "202504", Image: "spring-5.jpg"},
+				{ID: "202501", Image: "winter-4.jpg"},
+				{ID: "202410", Image: "autumn-2.jpg", BlurHash: "LKO2?U%2Tw=w"},
+				{ID: "202001", Image: "old.jpg"},
+			}))
+		})
+
+		It("keeps mirrored images and placeholders whose source did not change", func() {
+			existing := []model.FirestoreSeasonIndexItem{
+				{ID: "202410", Image: "https://cdn/a1.jpg", Source: "autumn-2.jpg", DominantColor: "#112233", BlurHash: "LKO2?U%2Tw=w"},
+				{ID: "202501", Image: "https://cdn/b2.jpg", Source: "winter-3.jpg", BlurHash: "LEHV6nWB2yk8"},
+			}
+			items := []model.FirestoreSeasonIndexItem{
+				{ID: "202410", Image: "autumn-2.jpg", Source: "autumn-2.jpg"},
+				{ID: "202501", Image: "winter-4.jpg", Source: "winter-4.jpg"},
+			}
+
+			got := MergeIndex(existing, items)
+
+			Expect(got).To(Equal([]model.FirestoreSeasonIndexItem{
+				{ID: "202501", Image: "winter-4.jpg", Source: "winter-4.jpg"},
+				existing[0],
+			}))
+		})
+	})
+})
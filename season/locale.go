@@ -0,0 +1,203 @@
+package season
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownLocale = errors.New("unknown season locale")
+	ErrInvalidFormat = errors.New("invalid season format")
+)
+
+type Locale string
+
+const (
+	LocaleZhHans Locale = "zh-Hans"
+	LocaleZhHant Locale = "zh-Hant"
+	LocaleJa     Locale = "ja"
+	LocaleEn     Locale = "en"
+
+	DefaultLocale = LocaleZhHans
+)
+
+// Style selects one of the per-locale layouts.
+type Style int
+
+const (
+	// StyleMonth names the season by its first month, e.g. "2025年4月新番" or "April 2025".
+	StyleMonth Style = iota
+	// StyleName names the season by its name, e.g. "2025年春季新番" or "Spring 2025".
+	StyleName
+	// StyleShort is the compact form, e.g. "2025春" or "2025 Spring".
+	StyleShort
+)
+
+// Layout tokens replaced by Season.Layout.
+const (
+	TokenYear      = "{year}"
+	TokenMonth     = "{month}"
+	TokenMonthName = "{month_name}"
+	TokenName      = "{name}"
+)
+
+// names are indexed by the season's position in the year, winter first.
+var names = map[Locale][4]string{
+	LocaleZhHans: {WinterName, SpringName, SummerName, AutumnName},
+	LocaleZhHant: {WinterName, SpringName, SummerName, AutumnName},
+	LocaleJa:     {WinterName, SpringName, SummerName, AutumnName},
+	LocaleEn:     {"Winter", "Spring", "Summer", "Autumn"},
+}
+
+var layouts = map[Locale]map[Style]string{
+	LocaleZhHans: {
+		StyleMonth: "{year}年{month}月新番",
+		StyleName:  "{year}年{name}季新番",
+		StyleShort: "{year}{name}",
+	},
+	LocaleZhHant: {
+		StyleMonth: "{year}年{month}月新番",
+		StyleName:  "{year}年{name}季新番",
+		StyleShort: "{year}{name}",
+	},
+	LocaleJa: {
+		StyleMonth: "{year}年{month}月期",
+		StyleName:  "{year}年{name}アニメ",
+		StyleShort: "{year}{name}",
+	},
+	LocaleEn: {
+		StyleMonth: "{month_name} {year}",
+		StyleName:  "{name} {year}",
+		StyleShort: "{year} {name}",
+	},
+}
+
+// ParseLocale maps a BCP 47 language tag such as "zh-TW" or "en-US" to a supported locale.
+func ParseLocale(tag string) (Locale, error) {
+	t := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+
+	switch {
+	case t == "zh-hant" || strings.HasPrefix(t, "zh-hant-") ||
+		t == "zh-tw" || t == "zh-hk" || t == "zh-mo":
+		return LocaleZhHant, nil
+	case t == "zh" || strings.HasPrefix(t, "zh-"):
+		return LocaleZhHans, nil
+	case t == "ja" || strings.HasPrefix(t, "ja-"):
+		return LocaleJa, nil
+	case t == "en" || strings.HasPrefix(t, "en-"):
+		return LocaleEn, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownLocale, tag)
+}
+
+// DisplayName returns the season name in the locale, falling back to DefaultLocale for unknown locales.
+func (s Season) DisplayName(locale Locale) string {
+	n, ok := names[locale]
+	if !ok {
+		n = names[DefaultLocale]
+	}
+	return n[s.position()]
+}
+
+// Format renders the season with the locale's layout for the style, falling back to DefaultLocale
+// and StyleShort.
+func (s Season) Format(locale Locale, style Style) string {
+	l, ok := layouts[locale]
+	if !ok {
+		locale, l = DefaultLocale, layouts[DefaultLocale]
+	}
+
+	layout, ok := l[style]
+	if !ok {
+		layout = l[StyleShort]
+	}
+
+	return s.Layout(locale, layout)
+}
+
+// Layout renders a custom layout, replacing TokenYear, TokenMonth, TokenMonthName and TokenName.
+func (s Season) Layout(locale Locale, layout string) string {
+	return strings.NewReplacer(
+		TokenYear, strconv.Itoa(s.year),
+		TokenMonth, strconv.Itoa(int(s.month)),
+		TokenMonthName, s.month.String(),
+		TokenName, s.DisplayName(locale),
+	).Replace(layout)
+}
+
+// position returns 0 for winter up to 3 for autumn.
+func (s Season) position() int {
+	return int(s.month-1) / 3
+}
+
+var (
+	yearRegex  = regexp.MustCompile(`\d{4}`)
+	monthRegex = regexp.MustCompile(`(\d{1,2})\s*月`)
+)
+
+// seasonWords maps lower-cased season names of every locale, plus common aliases, to their position.
+var seasonWords = map[string]int{
+	"winter": 0,
+	"spring": 1,
+	"summer": 2,
+	"autumn": 3,
+	"fall":   3,
+}
+
+// Parse parses a season id or a human readable season in any supported locale, such as
+// "2025年4月新番", "Spring 2025", "2025春", "2025年春アニメ" or "April 2025".
+// A month inside a season, e.g. "2025年5月", yields the season containing it.
+func Parse(s string) (Season, error) {
+	text := strings.TrimSpace(s)
+
+	if parsed, err := ParseID(text); err == nil {
+		return parsed, nil
+	}
+
+	years := yearRegex.FindAllString(text, -1)
+	if len(years) != 1 {
+		return Season{}, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+	}
+
+	year, _ := strconv.Atoi(years[0])
+	rest := strings.ToLower(strings.Replace(text, years[0], " ", 1))
+
+	if m := monthRegex.FindStringSubmatch(rest); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		if month < 1 || month > 12 {
+			return Season{}, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+		}
+		return of(year, time.Month(month)), nil
+	}
+
+	for _, word := range strings.FieldsFunc(rest, isSeparator) {
+		for m := time.January; m <= time.December; m++ {
+			name := strings.ToLower(m.String())
+			if word == name || word == name[:3] {
+				return of(year, m), nil
+			}
+		}
+
+		if p, ok := seasonWords[word]; ok {
+			return of(year, time.Month(p*3+1)), nil
+		}
+	}
+
+	// CJK names are not separated by spaces, "2025春" or "2025年春季新番".
+	for p, name := range names[LocaleZhHans] {
+		if strings.Contains(rest, name) {
+			return of(year, time.Month(p*3+1)), nil
+		}
+	}
+
+	return Season{}, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+}
+
+func isSeparator(r rune) bool {
+	return r == ' ' || r == ',' || r == '\'' || r == '-' || r == '/'
+}
@@ -0,0 +1,113 @@
+package season
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("season locale unit tests", func() {
+	Describe("Format", func() {
+		DescribeTable("renders the locale layouts",
+			func(locale Locale, style Style, want string) {
+				Expect(spring(2025).Format(locale, style)).To(Equal(want))
+			},
+			Entry("zh-Hans month", LocaleZhHans, StyleMonth, "2025年4月新番"),
+			Entry("zh-Hans name", LocaleZhHans, StyleName, "2025年春季新番"),
+			Entry("zh-Hans short", LocaleZhHans, StyleShort, "2025春"),
+			Entry("zh-Hant month", LocaleZhHant, StyleMonth, "2025年4月新番"),
+			Entry("ja month", LocaleJa, StyleMonth, "2025年4月期"),
+			Entry("ja name", LocaleJa, StyleName, "2025年春アニメ"),
+			Entry("en month", LocaleEn, StyleMonth, "April 2025"),
+			Entry("en name", LocaleEn, StyleName, "Spring 2025"),
+			Entry("unknown locale", Locale("fr"), StyleShort, "2025春"),
+		)
+
+		It("renders custom layouts", func() {
+			Expect(autumn(2024).Layout(LocaleEn, "{name} '{year}")).To(Equal("Autumn '2024"))
+		})
+	})
+
+	Describe("DisplayName", func() {
+		It("returns the localised name", func() {
+			Expect(winter(2025).DisplayName(LocaleJa)).To(Equal("冬"))
+			Expect(summer(2025).DisplayName(LocaleEn)).To(Equal("Summer"))
+		})
+	})
+
+	Describe("ParseLocale", func() {
+		DescribeTable("maps language tags",
+			func(tag string, want Locale) {
+				got, err := ParseLocale(tag)
+				Expect(err).To(BeNil())
+				Expect(got).To(Equal(want))
+			},
+			Entry("zh-CN", "zh-CN", LocaleZhHans),
+			Entry("zh-TW", "zh_TW", LocaleZhHant),
+			Entry("zh-Hant-HK", "zh-Hant-HK", LocaleZhHant),
+			Entry("ja-JP", "ja-JP", LocaleJa),
+			Entry("en-US", "en-US", LocaleEn),
+		)
+
+		It("returns error for unsupported tags", func() {
+			_, err := ParseLocale("fr-FR")
+			Expect(errors.Is(err, ErrUnknownLocale)).To(BeTrue())
+		})
+	})
+
+	Describe("Parse", func() {
+		DescribeTable("parses human formats",
+			func(text string, want string) {
+				got, err := Parse(text)
+				Expect(err).To(BeNil())
+				Expect(got.ID()).To(Equal(want))
+			},
+			Entry("id", "202504", "202504"),
+			Entry("zh month", "2025年4月新番", "202504"),
+			Entry("zh month inside season", "2025年5月", "202504"),
+			Entry("zh short", "2025春", "202504"),
+			Entry("zh name", "2024年秋季新番", "202410"),
+			Entry("ja month", "2025年7月期", "202507"),
+			Entry("ja name", "2025年冬アニメ", "202501"),
+			Entry("en name", "Spring 2025", "202504"),
+			Entry("en fall", "fall 2024", "202410"),
+			Entry("en month", "July 2025", "202507"),
+			Entry("en short month", "Oct 2024", "202410"),
+		)
+
+		locales := []Locale{LocaleZhHans, LocaleZhHant, LocaleJa, LocaleEn}
+
+		It("covers every locale with layouts", func() {
+			Expect(locales).To(HaveLen(len(layouts)))
+			for _, locale := range locales {
+				Expect(layouts).To(HaveKey(locale))
+			}
+		})
+
+		for _, s := range []Season{winter(2025), spring(2025), summer(2025), autumn(2025)} {
+			for _, locale := range locales {
+				for _, style := range []Style{StyleMonth, StyleName, StyleShort} {
+					s, locale, style := s, locale, style
+					It(fmt.Sprintf("round trips %s (%s, style %d)", s.Format(locale, style), locale, style), func() {
+						got, err := Parse(s.Format(locale, style))
+						Expect(err).To(BeNil())
+						Expect(got.ID()).To(Equal(s.ID()))
+					})
+				}
+			}
+		}
+
+		DescribeTable("returns error for invalid formats",
+			func(text string) {
+				_, err := Parse(text)
+				Expect(errors.Is(err, ErrInvalidFormat)).To(BeTrue())
+			},
+			Entry("empty", ""),
+			Entry("no year", "春季新番"),
+			Entry("no season", "2025年新番"),
+			Entry("invalid month", "2025年13月"),
+		)
+	})
+})
@@ -2,6 +2,7 @@ package season
 
 import (
 	"fmt"
+	"time"
 )
 
 const (
@@ -18,32 +19,36 @@ const (
 
 func winter(year int) Season {
 	return Season{
-		id:   fmt.Sprintf("%d%s", year, WinterMonth),
-		name: WinterName,
-		year: year,
+		id:    fmt.Sprintf("%d%s", year, WinterMonth),
+		name:  WinterName,
+		year:  year,
+		month: time.January,
 	}
 }
 
 func spring(year int) Season {
 	return Season{
-		id:   fmt.Sprintf("%d%s", year, SpringMonth),
-		name: SpringName,
-		year: year,
+		id:    fmt.Sprintf("%d%s", year, SpringMonth),
+		name:  SpringName,
+		year:  year,
+		month: time.April,
 	}
 }
 
 func summer(year int) Season {
 	return Season{
-		id:   fmt.Sprintf("%d%s", year, SummerMonth),
-		name: SummerName,
-		year: year,
+		id:    fmt.Sprintf("%d%s", year, SummerMonth),
+		name:  SummerName,
+		year:  year,
+		month: time.July,
 	}
 }
 
 func autumn(year int) Season {
 	return Season{
-		id:   fmt.Sprintf("%d%s", year, AutumnMonth),
-		name: AutumnName,
-		year: year,
+		id:    fmt.Sprintf("%d%s", year, AutumnMonth),
+		name:  AutumnName,
+		year:  year,
+		month: time.October,
 	}
 }
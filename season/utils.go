@@ -1,47 +1,106 @@
 package season
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/bangumilite/bangumilite-component/model"
+	"strconv"
 	"time"
 )
 
-type Season struct {
-	id   string
-	name string
-	year int
+var ErrInvalidID = errors.New("invalid season id")
 
-	t time.Time // internally used to track the system local time
+type Season struct {
+	id    string
+	name  string
+	year  int
+	month time.Month // first month of the season
 }
 
+// New returns the season of the instant in DefaultLocation, without any grace window.
 func New(t time.Time) Season {
-	y := t.Year()
-	var s Season
-	switch t.Month() {
-	case 1, 2, 3:
-		s = winter(y)
-	case 4, 5, 6:
-		s = spring(y)
-	case 7, 8, 9:
-		s = summer(y)
-	case 10, 11, 12:
-		s = autumn(y)
+	local := t.In(DefaultLocation)
+	return of(local.Year(), local.Month())
+}
+
+// ParseID parses a season id such as "202504".
+func ParseID(id string) (Season, error) {
+	if len(id) != 6 {
+		return Season{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	// strconv.Atoi accepts signs, e.g. "-123" or "+202".
+	for i := 0; i < 4; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return Season{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+		}
+	}
+
+	year, err := strconv.Atoi(id[:4])
+	if err != nil {
+		return Season{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	switch id[4:] {
+	case WinterMonth:
+		return winter(year), nil
+	case SpringMonth:
+		return spring(year), nil
+	case SummerMonth:
+		return summer(year), nil
+	case AutumnMonth:
+		return autumn(year), nil
+	}
+
+	return Season{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+}
+
+// Range returns every season from from to to, both inclusive, or nil if from is after to.
+func Range(from Season, to Season) []Season {
+	var seasons []Season
+	for s := from; !to.Before(s); s = s.Next() {
+		seasons = append(seasons, s)
 	}
-	s.t = t
-	return s
+	return seasons
 }
 
 func (s Season) Next() Season {
-	switch s.t.Month() {
-	case 1, 2, 3:
-		return spring(s.year)
-	case 4, 5, 6:
-		return summer(s.year)
-	case 7, 8, 9:
-		return autumn(s.year)
-	case 10, 11, 12:
-		return winter(s.year + 1)
+	return of(s.year, s.month+3)
+}
+
+func (s Season) Prev() Season {
+	return of(s.year, s.month-3)
+}
+
+// Compare returns -1, 0 or +1 depending on whether s is before, the same as or after other.
+func (s Season) Compare(other Season) int {
+	switch {
+	case s.index() < other.index():
+		return -1
+	case s.index() > other.index():
+		return 1
 	}
-	return Season{}
+	return 0
+}
+
+func (s Season) Before(other Season) bool {
+	return s.Compare(other) < 0
+}
+
+// Start returns the first instant of the season in DefaultLocation, use Classifier.Start for other zones.
+func (s Season) Start() time.Time {
+	return s.start(DefaultLocation)
+}
+
+// End returns the first instant after the season in DefaultLocation, i.e. the start of the next season.
+// Use Classifier.End for other zones.
+func (s Season) End() time.Time {
+	return s.Next().Start()
+}
+
+func (s Season) start(loc *time.Location) time.Time {
+	return time.Date(s.year, s.month, 1, 0, 0, 0, 0, loc)
 }
 
 func (s Season) ID() string {
@@ -59,3 +118,65 @@ func (s Season) Year() int {
 func (s Season) ToString() string {
 	return fmt.Sprintf("id:%s,name:%s,year:%d", s.id, s.name, s.year)
 }
+
+// MarshalJSON encodes the season as its id, e.g. "202504", and the zero season as "".
+func (s Season) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.id)
+}
+
+// UnmarshalJSON decodes a season id, "" and null decode to the zero season.
+func (s *Season) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err != nil {
+		return err
+	}
+
+	if id == "" {
+		*s = Season{}
+		return nil
+	}
+
+	parsed, err := ParseID(id)
+	if err != nil {
+		return err
+	}
+
+	*s = parsed
+	return nil
+}
+
+// Firestore returns the stored form of the season, the firestore client has no custom marshalling hook.
+// The name and year are derived from the id and are not stored.
+func (s Season) Firestore() model.SeasonID {
+	return model.SeasonID(s.id)
+}
+
+// FromFirestore restores a season from its stored form, the empty id restores the zero season.
+func FromFirestore(id model.SeasonID) (Season, error) {
+	if id == "" {
+		return Season{}, nil
+	}
+
+	return ParseID(string(id))
+}
+
+// index counts seasons since year 0 so that seasons can be compared.
+func (s Season) index() int {
+	return s.year*4 + int(s.month-1)/3
+}
+
+// of returns the season containing the given month, months outside 1-12 roll over into adjacent years.
+func of(year int, month time.Month) Season {
+	t := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+
+	switch t.Month() {
+	case 1, 2, 3:
+		return winter(t.Year())
+	case 4, 5, 6:
+		return spring(t.Year())
+	case 7, 8, 9:
+		return summer(t.Year())
+	default:
+		return autumn(t.Year())
+	}
+}
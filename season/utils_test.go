@@ -1,6 +1,8 @@
 package season
 
 import (
+	"encoding/json"
+	"github.com/bangumilite/bangumilite-component/model"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"time"
@@ -47,6 +49,13 @@ var _ = Describe("season seasons unit tests", func() {
 			Expect(got.Name()).To(Equal(want.Name()))
 			Expect(got.Year()).To(Equal(want.Year()))
 		})
+
+		It("should classify in Japan time", func() {
+			t := time.Date(2025, 3, 31, 16, 0, 0, 0, time.UTC)
+			got := New(t)
+
+			Expect(got.ID()).To(Equal("202504"))
+		})
 	})
 
 	Describe("Next", func() {
@@ -90,4 +99,113 @@ var _ = Describe("season seasons unit tests", func() {
 			Expect(got.Year()).To(Equal(want.Year()))
 		})
 	})
+
+	Describe("Next and Prev", func() {
+		It("chains across years", func() {
+			got := New(time.Date(2024, 11, 5, 0, 0, 0, 0, time.UTC)).Next().Next()
+
+			Expect(got.ID()).To(Equal("202504"))
+		})
+
+		It("returns the previous season", func() {
+			got := New(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)).Prev()
+
+			Expect(got.ID()).To(Equal("202410"))
+			Expect(got.Prev().Prev().Prev().Prev().ID()).To(Equal("202310"))
+		})
+	})
+
+	Describe("ParseID", func() {
+		It("parses a valid id", func() {
+			got, err := ParseID("202504")
+
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(spring(2025)))
+		})
+
+		It("returns error for an invalid id", func() {
+			for _, id := range []string{"", "2025", "202502", "abcd04", "2025040", "-12304", "+20204", " 20204"} {
+				_, err := ParseID(id)
+				Expect(err).To(MatchError(ErrInvalidID))
+			}
+		})
+	})
+
+	Describe("Range", func() {
+		It("returns every season in between, both inclusive", func() {
+			got := Range(autumn(2024), summer(2025))
+
+			Expect(got).To(Equal([]Season{autumn(2024), winter(2025), spring(2025), summer(2025)}))
+		})
+
+		It("returns nil if from is after to", func() {
+			Expect(Range(summer(2025), spring(2025))).To(BeNil())
+		})
+	})
+
+	Describe("Compare", func() {
+		It("orders seasons", func() {
+			Expect(autumn(2024).Compare(winter(2025))).To(Equal(-1))
+			Expect(winter(2025).Compare(autumn(2024))).To(Equal(1))
+			Expect(spring(2025).Compare(spring(2025))).To(Equal(0))
+			Expect(autumn(2024).Before(winter(2025))).To(BeTrue())
+		})
+	})
+
+	Describe("Start and End", func() {
+		It("returns the time bounds of the season", func() {
+			s := autumn(2024)
+
+			Expect(s.Start()).To(Equal(time.Date(2024, 10, 1, 0, 0, 0, 0, DefaultLocation)))
+			Expect(s.End()).To(Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, DefaultLocation)))
+			Expect(s.Start().Equal(time.Date(2024, 9, 30, 15, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+	})
+
+	Describe("marshalling", func() {
+		It("round trips through JSON as the id", func() {
+			data, err := json.Marshal(map[string]Season{"season": spring(2025)})
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`{"season":"202504"}`))
+
+			var got map[string]Season
+			Expect(json.Unmarshal(data, &got)).To(Succeed())
+			Expect(got["season"]).To(Equal(spring(2025)))
+		})
+
+		It("round trips the zero season", func() {
+			data, err := json.Marshal(map[string]Season{"season": {}})
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`{"season":""}`))
+
+			got := map[string]Season{}
+			Expect(json.Unmarshal(data, &got)).To(Succeed())
+			Expect(got["season"]).To(Equal(Season{}))
+
+			s := spring(2025)
+			Expect(json.Unmarshal([]byte(`null`), &s)).To(Succeed())
+			Expect(s).To(Equal(Season{}))
+		})
+
+		It("rejects invalid JSON ids", func() {
+			var got Season
+			Expect(json.Unmarshal([]byte(`"202502"`), &got)).ToNot(Succeed())
+		})
+
+		It("round trips through the firestore form", func() {
+			stored := summer(2025).Firestore()
+			Expect(stored).To(Equal(model.SeasonID("202507")))
+
+			got, err := FromFirestore(stored)
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(summer(2025)))
+
+			got, err = FromFirestore(Season{}.Firestore())
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(Season{}))
+
+			_, err = FromFirestore("2025")
+			Expect(err).To(MatchError(ErrInvalidID))
+		})
+	})
 })
@@ -0,0 +1,194 @@
+package tag
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tag is a tag with its vote count. model.BangumiTag is an alias of it, so that model can use the
+// normaliser without an import cycle.
+type Tag struct {
+	Name  string `json:"name" firestore:"name"`
+	Count int    `json:"count" firestore:"count,omitempty"`
+}
+
+// YearTagRegex matches year and season tags such as "2024", "2024年" and "2024年4月".
+var YearTagRegex = regexp.MustCompile(`^\d{4}(年(\d{1,2}月)?)?$`)
+
+// NoiseTags describe the format or origin of a subject rather than its content.
+var NoiseTags = []string{
+	"TV", "TV动画", "TVアニメ", "动画", "アニメ", "日本", "日本动画", "中国", "美国", "原创", "漫画改", "轻小说改",
+	"小说改", "游戏改", "剧场版", "OVA", "OAD", "WEB", "短片", "季番", "新番", "续作", "未上映", "未完结", "已完结",
+}
+
+// DefaultSynonyms maps common tag variants to the canonical tag they are merged into.
+var DefaultSynonyms = map[string]string{
+	"TV":      "TV动画",
+	"TVA":     "TV动画",
+	"TVアニメ":   "TV动画",
+	"剧场版动画":   "剧场版",
+	"Movie":   "剧场版",
+	"漫改":      "漫画改",
+	"漫画改编":    "漫画改",
+	"轻改":      "轻小说改",
+	"轻小说改编":   "轻小说改",
+	"游戏改编":    "游戏改",
+	"原创动画":    "原创",
+	"オリジナル":   "原创",
+	"GL":      "百合",
+	"Yuri":    "百合",
+	"BL":      "耽美",
+	"后宫向":     "后宫",
+	"ハーレム":    "后宫",
+	"Sci-Fi":  "科幻",
+	"SF":      "科幻",
+	"Mecha":   "机战",
+	"机甲":      "机战",
+	"Romance": "恋爱",
+	"恋爱喜剧":    "恋爱",
+	"ラブコメ":    "恋爱",
+}
+
+// Normalizer maps tag variants to a canonical tag: full-width and half-width forms, case,
+// traditional and simplified characters and the configured synonyms. Case and traditional characters
+// are only folded in comparison keys, display names keep their spelling so that Japanese tags are
+// not rewritten into simplified Chinese.
+type Normalizer struct {
+	synonyms map[string]string // folded variant -> canonical display name
+	noise    map[string]bool   // keys of NoiseTags
+}
+
+// DefaultNormalizer uses DefaultSynonyms, it backs model.BangumiTags.TopTags.
+var DefaultNormalizer = NewNormalizer(DefaultSynonyms)
+
+func NewNormalizer(synonyms map[string]string) *Normalizer {
+	n := &Normalizer{
+		synonyms: make(map[string]string, len(synonyms)),
+		noise:    make(map[string]bool, len(NoiseTags)),
+	}
+
+	for variant, canonical := range synonyms {
+		n.synonyms[fold(variant)] = norm.NFKC.String(strings.TrimSpace(canonical))
+	}
+
+	for _, name := range NoiseTags {
+		n.noise[n.Key(name)] = true
+	}
+
+	return n
+}
+
+// Key returns the comparison key of a tag, two tags are the same tag when their keys are equal.
+func (n *Normalizer) Key(name string) string {
+	return fold(n.Normalize(name))
+}
+
+// Normalize returns the canonical display name of a tag.
+func (n *Normalizer) Normalize(name string) string {
+	cleaned := norm.NFKC.String(strings.Join(strings.Fields(name), " "))
+
+	if canonical, ok := n.synonyms[fold(cleaned)]; ok {
+		return canonical
+	}
+
+	return cleaned
+}
+
+// IsNoise reports whether the tag, once normalised, is a year or format tag.
+func (n *Normalizer) IsNoise(name string) bool {
+	key := n.Key(name)
+	return n.noise[key] || YearTagRegex.MatchString(key)
+}
+
+// TopTags merges the tags and returns at most limit of them ordered by count, skipping noise tags and
+// the excluded names. Tags with equal counts keep their original order.
+func (n *Normalizer) TopTags(tags []Tag, limit int, exclude ...string) []Tag {
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[n.Key(name)] = true
+	}
+
+	var res []Tag
+	for _, tag := range n.Merge(tags) {
+		if len(res) >= limit {
+			break
+		}
+
+		if n.IsNoise(tag.Name) || excluded[n.Key(tag.Name)] {
+			continue
+		}
+
+		res = append(res, tag)
+	}
+
+	return res
+}
+
+// Merge normalises the tags and sums the counts of tags sharing a key, ordered by count.
+// A merged tag is named after its synonym if one is configured, otherwise after its most voted variant.
+func (n *Normalizer) Merge(tags []Tag) []Tag {
+	type merged struct {
+		tag       Tag
+		bestCount int
+		order     int
+	}
+
+	byKey := make(map[string]*merged)
+	for i, tag := range tags {
+		name := n.Normalize(tag.Name)
+		if len(name) == 0 {
+			continue
+		}
+
+		key := fold(name)
+		m, ok := byKey[key]
+		if !ok {
+			byKey[key] = &merged{
+				tag:       Tag{Name: name, Count: tag.Count},
+				bestCount: tag.Count,
+				order:     i,
+			}
+			continue
+		}
+
+		m.tag.Count += tag.Count
+		if _, synonym := n.synonyms[key]; !synonym && tag.Count > m.bestCount {
+			m.tag.Name = name
+			m.bestCount = tag.Count
+		}
+	}
+
+	all := make([]*merged, 0, len(byKey))
+	for _, m := range byKey {
+		all = append(all, m)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].tag.Count != all[j].tag.Count {
+			return all[i].tag.Count > all[j].tag.Count
+		}
+		return all[i].order < all[j].order
+	})
+
+	res := make([]Tag, len(all))
+	for i, m := range all {
+		res[i] = m.tag
+	}
+
+	return res
+}
+
+func simplify(s string) string {
+	return strings.Map(func(r rune) rune {
+		if simplified, ok := traditionalToSimplified[r]; ok {
+			return simplified
+		}
+		return r
+	}, s)
+}
+
+func fold(s string) string {
+	return strings.ToLower(simplify(norm.NFKC.String(strings.TrimSpace(s))))
+}
@@ -0,0 +1,132 @@
+package tag
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tag normalizer unit tests", func() {
+	var normalizer *Normalizer
+
+	BeforeEach(func() {
+		normalizer = NewNormalizer(DefaultSynonyms)
+	})
+
+	Describe("Normalize", func() {
+		It("folds full-width characters", func() {
+			Expect(normalizer.Normalize("ＳＦ")).To(Equal("科幻"))
+			Expect(normalizer.Normalize("京都アニメーション　")).To(Equal("京都アニメーション"))
+		})
+
+		It("keeps the spelling of traditional characters", func() {
+			Expect(normalizer.Normalize("戀愛")).To(Equal("戀愛"))
+			Expect(normalizer.Normalize("輕小說改")).To(Equal("輕小說改"))
+		})
+
+		It("keeps Japanese tags intact", func() {
+			Expect(normalizer.Normalize("僕のヒーローアカデミア")).To(Equal("僕のヒーローアカデミア"))
+			Expect(normalizer.Normalize("戦闘")).To(Equal("戦闘"))
+			Expect(normalizer.Normalize("後宮")).To(Equal("後宮"))
+			Expect(normalizer.Normalize("裏切り")).To(Equal("裏切り"))
+			Expect(normalizer.Normalize("機動戦士")).To(Equal("機動戦士"))
+		})
+
+		It("resolves synonyms case-insensitively", func() {
+			Expect(normalizer.Normalize("tv")).To(Equal("TV动画"))
+			Expect(normalizer.Normalize("yuri")).To(Equal("百合"))
+		})
+	})
+
+	Describe("Key", func() {
+		It("is equal for variants of the same tag", func() {
+			Expect(normalizer.Key("Ｏｒｉｇｉｎａｌ")).To(Equal(normalizer.Key("original")))
+			Expect(normalizer.Key("TV")).To(Equal(normalizer.Key("TV动画")))
+			Expect(normalizer.Key("戀愛")).To(Equal(normalizer.Key("恋爱")))
+			Expect(normalizer.Key("輕小說改")).To(Equal(normalizer.Key("轻小说改")))
+		})
+
+		It("does not fold Japanese kanji into other words", func() {
+			Expect(normalizer.Key("僕")).ToNot(Equal(normalizer.Key("仆")))
+			Expect(normalizer.Key("後宮")).ToNot(Equal(normalizer.Key("后宫")))
+			Expect(normalizer.Key("戦闘")).ToNot(Equal(normalizer.Key("戦斗")))
+		})
+	})
+
+	Describe("IsNoise", func() {
+		It("detects year and format tags after normalisation", func() {
+			Expect(normalizer.IsNoise("２０２４")).To(BeTrue())
+			Expect(normalizer.IsNoise("2024年4月")).To(BeTrue())
+			Expect(normalizer.IsNoise("TVA")).To(BeTrue())
+			Expect(normalizer.IsNoise("日本")).To(BeTrue())
+			Expect(normalizer.IsNoise("百合")).To(BeFalse())
+		})
+	})
+
+	Describe("Merge", func() {
+		It("sums the counts of merged variants and orders by count", func() {
+			tags := []Tag{
+				{Name: "TV", Count: 50},
+				{Name: "百合", Count: 30},
+				{Name: "TV动画", Count: 20},
+				{Name: "GL", Count: 25},
+				{Name: "校園", Count: 10},
+			}
+
+			got := normalizer.Merge(tags)
+
+			Expect(got).To(Equal([]Tag{
+				{Name: "TV动画", Count: 70},
+				{Name: "百合", Count: 55},
+				{Name: "校園", Count: 10},
+			}))
+		})
+
+		It("names merged variants after the most voted spelling", func() {
+			tags := []Tag{
+				{Name: "戀愛", Count: 5},
+				{Name: "恋爱", Count: 10},
+				{Name: "僕のヒーローアカデミア", Count: 8},
+				{Name: "戦闘", Count: 3},
+			}
+
+			got := normalizer.Merge(tags)
+
+			Expect(got).To(Equal([]Tag{
+				{Name: "恋爱", Count: 15},
+				{Name: "僕のヒーローアカデミア", Count: 8},
+				{Name: "戦闘", Count: 3},
+			}))
+		})
+
+		It("feeds top tags without noise", func() {
+			tags := []Tag{
+				{Name: "2024", Count: 90},
+				{Name: "日本", Count: 80},
+				{Name: "TV", Count: 70},
+				{Name: "百合", Count: 60},
+				{Name: "校园", Count: 50},
+				{Name: "音乐", Count: 40},
+			}
+
+			got := normalizer.TopTags(tags, 2, "音乐")
+
+			Expect(got).To(Equal([]Tag{
+				{Name: "百合", Count: 60},
+				{Name: "校园", Count: 50},
+			}))
+		})
+
+		It("dedups top tags through the synonyms", func() {
+			tags := []Tag{
+				{Name: "恋爱", Count: 30},
+				{Name: "恋愛", Count: 20},
+				{Name: "ＴＶアニメ", Count: 10},
+				{Name: "TV动画", Count: 5},
+				{Name: "2024年4月", Count: 4},
+			}
+
+			Expect(normalizer.TopTags(tags, 5)).To(Equal([]Tag{{Name: "恋爱", Count: 50}}))
+			Expect(normalizer.IsNoise("tva")).To(BeTrue())
+		})
+	})
+})
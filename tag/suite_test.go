@@ -0,0 +1,12 @@
+package tag
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestTag(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "tag test suite")
+}
@@ -0,0 +1,65 @@
+package tag
+
+// traditionalToSimplified maps the traditional characters common in Bangumi tags to their simplified forms,
+// it is only used for comparison keys. It is not a general purpose converter: characters whose simplified
+// form merges distinct words, and characters that Japanese uses with another meaning, are not listed.
+var traditionalToSimplified = map[rune]rune{
+	'亂': '乱', '亞': '亚', '來': '来', '侶': '侣', '俠': '侠', '個': '个', '們': '们', '倫': '伦', '偉': '伟',
+	'偵': '侦', '偽': '伪', '傑': '杰', '備': '备', '傳': '传', '傷': '伤', '價': '价', '儀': '仪', '億': '亿',
+	'優': '优', '兇': '凶', '兒': '儿', '兩': '两', '則': '则', '創': '创', '劃': '划', '劇': '剧', '劍': '剑',
+	'動': '动', '務': '务', '勝': '胜', '勞': '劳', '勢': '势', '勵': '励', '匯': '汇', '區': '区', '員': '员',
+	'問': '问', '喚': '唤', '喪': '丧', '單': '单', '嚇': '吓', '國': '国', '圍': '围', '園': '园', '圖': '图',
+	'團': '团', '報': '报', '場': '场', '塊': '块', '墜': '坠', '墮': '堕', '壓': '压', '壘': '垒', '壞': '坏',
+	'壯': '壮', '夠': '够', '夢': '梦', '夥': '伙', '奧': '奥', '奪': '夺', '奮': '奋', '姦': '奸', '娛': '娱',
+	'婦': '妇', '媽': '妈', '嬌': '娇', '嬰': '婴', '孫': '孙', '學': '学', '宮': '宫', '寢': '寝', '實': '实',
+	'寧': '宁', '審': '审', '寫': '写', '寵': '宠', '寶': '宝', '將': '将', '專': '专', '對': '对', '導': '导',
+	'屬': '属', '島': '岛', '嶺': '岭', '嶼': '屿', '帥': '帅', '師': '师', '幣': '币', '幫': '帮', '庫': '库',
+	'廚': '厨', '廟': '庙', '廢': '废', '廣': '广', '廳': '厅', '張': '张', '強': '强', '彈': '弹', '彌': '弥',
+	'彎': '弯', '從': '从', '惡': '恶', '愛': '爱', '態': '态', '慾': '欲', '憂': '忧', '憐': '怜', '憤': '愤',
+	'憲': '宪', '憶': '忆', '應': '应', '懸': '悬', '戀': '恋', '戰': '战', '戲': '戏', '戶': '户', '擇': '择',
+	'擊': '击', '據': '据', '擬': '拟', '擾': '扰', '攝': '摄', '敗': '败', '數': '数', '時': '时', '書': '书',
+	'會': '会', '東': '东', '條': '条', '楓': '枫', '業': '业', '構': '构', '槍': '枪', '槳': '桨', '樂': '乐',
+	'標': '标', '樣': '样', '樸': '朴', '樹': '树', '機': '机', '橫': '横', '櫻': '樱', '權': '权', '歐': '欧',
+	'歡': '欢', '歲': '岁', '歷': '历', '歸': '归', '殘': '残', '殤': '殇', '殭': '僵', '殺': '杀', '殼': '壳',
+	'氣': '气', '決': '决', '淚': '泪', '淨': '净', '溫': '温', '滅': '灭', '滿': '满', '漁': '渔', '漢': '汉',
+	'潔': '洁', '潛': '潜', '潤': '润', '澤': '泽', '濕': '湿', '濟': '济', '濤': '涛', '瀨': '濑', '灣': '湾',
+	'災': '灾', '為': '为', '無': '无', '煉': '炼', '煙': '烟', '熱': '热', '燈': '灯', '燒': '烧', '營': '营',
+	'燼': '烬', '爐': '炉', '爭': '争', '爲': '为', '爺': '爷', '爾': '尔', '狀': '状', '獄': '狱', '獎': '奖',
+	'獨': '独', '獲': '获', '獵': '猎', '獸': '兽', '現': '现', '瑪': '玛', '環': '环', '產': '产', '畫': '画',
+	'異': '异', '當': '当', '療': '疗', '癡': '痴', '發': '发', '盡': '尽', '監': '监', '眾': '众', '碼': '码',
+	'礙': '碍', '礦': '矿', '禍': '祸', '禮': '礼', '稱': '称', '穩': '稳', '競': '竞', '筆': '笔', '節': '节',
+	'簡': '简', '籃': '篮', '籤': '签', '糧': '粮', '糾': '纠', '紀': '纪', '紅': '红', '紋': '纹', '純': '纯',
+	'紗': '纱', '紙': '纸', '級': '级', '紳': '绅', '組': '组', '結': '结', '絡': '络', '統': '统', '絲': '丝',
+	'綁': '绑', '經': '经', '綜': '综', '綠': '绿', '綢': '绸', '維': '维', '網': '网', '綾': '绫', '線': '线',
+	'緞': '缎', '緣': '缘', '練': '练', '縛': '缚', '縣': '县', '縮': '缩', '縱': '纵', '總': '总', '織': '织',
+	'繪': '绘', '繼': '继', '續': '续', '罰': '罚', '羅': '罗', '義': '义', '習': '习', '聖': '圣', '聞': '闻',
+	'聯': '联', '聲': '声', '職': '职', '聽': '听', '腦': '脑', '膚': '肤', '臉': '脸', '臨': '临', '臺': '台',
+	'與': '与', '舉': '举', '舊': '旧', '艙': '舱', '艦': '舰', '艷': '艳', '莊': '庄', '華': '华', '萬': '万',
+	'葉': '叶', '蓮': '莲', '蕭': '萧', '薩': '萨', '藍': '蓝', '藝': '艺', '藥': '药', '蘆': '芦', '蘇': '苏',
+	'蘋': '苹', '蘭': '兰', '蘿': '萝', '處': '处', '虛': '虚', '號': '号', '蝦': '虾', '螢': '萤', '蟲': '虫',
+	'蠶': '蚕', '術': '术', '衛': '卫', '衝': '冲', '補': '补', '裝': '装', '襲': '袭', '見': '见', '規': '规',
+	'視': '视', '親': '亲', '覺': '觉', '觀': '观', '觸': '触', '計': '计', '訊': '讯', '訓': '训', '記': '记',
+	'設': '设', '詛': '诅', '詞': '词', '試': '试', '詩': '诗', '詭': '诡', '話': '话', '該': '该', '誌': '志',
+	'認': '认', '語': '语', '說': '说', '課': '课', '請': '请', '論': '论', '謀': '谋', '謊': '谎', '謎': '谜',
+	'謝': '谢', '證': '证', '識': '识', '譚': '谭', '譜': '谱', '譯': '译', '議': '议', '護': '护', '讀': '读',
+	'變': '变', '讓': '让', '豐': '丰', '豔': '艳', '豬': '猪', '貓': '猫', '貞': '贞', '負': '负', '貨': '货',
+	'貪': '贪', '貳': '贰', '貴': '贵', '買': '买', '費': '费', '貼': '贴', '資': '资', '賊': '贼', '賞': '赏',
+	'賢': '贤', '賣': '卖', '質': '质', '賴': '赖', '賽': '赛', '贈': '赠', '贏': '赢', '趙': '赵', '蹤': '踪',
+	'躍': '跃', '車': '车', '軌': '轨', '軍': '军', '軟': '软', '載': '载', '輕': '轻', '輛': '辆', '輝': '辉',
+	'輪': '轮', '輯': '辑', '輸': '输', '轉': '转', '轟': '轰', '辦': '办', '農': '农', '這': '这', '進': '进',
+	'運': '运', '過': '过', '達': '达', '遞': '递', '遠': '远', '遲': '迟', '選': '选', '遺': '遗', '邁': '迈',
+	'還': '还', '邊': '边', '邏': '逻', '郵': '邮', '鄉': '乡', '鄰': '邻', '醃': '腌', '醫': '医', '醬': '酱',
+	'釋': '释', '釣': '钓', '銀': '银', '鋒': '锋', '鋪': '铺', '鋸': '锯', '鋼': '钢', '錄': '录', '錢': '钱',
+	'錦': '锦', '錯': '错', '鍵': '键', '鎖': '锁', '鎧': '铠', '鎮': '镇', '鏈': '链', '鏡': '镜', '鐘': '钟',
+	'鐮': '镰', '鐵': '铁', '鑰': '钥', '鑽': '钻', '長': '长', '門': '门', '閃': '闪', '開': '开', '間': '间',
+	'閱': '阅', '關': '关', '陣': '阵', '陰': '阴', '陸': '陆', '陽': '阳', '隊': '队', '險': '险', '隱': '隐',
+	'雖': '虽', '雙': '双', '雜': '杂', '雞': '鸡', '離': '离', '難': '难', '雲': '云', '電': '电', '霧': '雾',
+	'靂': '雳', '靈': '灵', '靜': '静', '韓': '韩', '韻': '韵', '響': '响', '頂': '顶', '預': '预', '頒': '颁',
+	'領': '领', '頭': '头', '頹': '颓', '頻': '频', '題': '题', '顏': '颜', '願': '愿', '類': '类', '顧': '顾',
+	'顯': '显', '風': '风', '颶': '飓', '飄': '飘', '飛': '飞', '飢': '饥', '飯': '饭', '飾': '饰', '餅': '饼',
+	'養': '养', '餓': '饿', '館': '馆', '饑': '饥', '馬': '马', '駕': '驾', '駛': '驶', '騎': '骑', '騙': '骗',
+	'騷': '骚', '驅': '驱', '驗': '验', '驚': '惊', '驢': '驴', '體': '体', '鬧': '闹', '魚': '鱼', '魯': '鲁',
+	'鯊': '鲨', '鯨': '鲸', '鳥': '鸟', '鳳': '凤', '鳴': '鸣', '鴨': '鸭', '鶴': '鹤', '鷹': '鹰', '麗': '丽',
+	'麥': '麦', '麼': '么', '黃': '黄', '點': '点', '黨': '党', '齊': '齐', '齋': '斋', '齒': '齿', '齡': '龄',
+	'龍': '龙', '龜': '龟',
+}
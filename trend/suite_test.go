@@ -0,0 +1,12 @@
+package trend
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestTrend(c *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(c, "trend test suite")
+}
@@ -0,0 +1,182 @@
+package trend
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	"math"
+	"sort"
+	"time"
+)
+
+// Weights configures how collection growth turns into a trending score.
+type Weights struct {
+	Doing   float64
+	Wish    float64
+	Collect float64
+	OnHold  float64
+	Dropped float64
+
+	// HalfLife is the age after which the growth observed in a snapshot counts half.
+	HalfLife time.Duration
+
+	// Damping is added to the collection total a delta is divided by, so titles with
+	// only a handful of collections cannot top the list from a few new entries.
+	Damping float64
+}
+
+var DefaultWeights = Weights{
+	Doing:    1.0,
+	Wish:     0.6,
+	Collect:  0.3,
+	OnHold:   0,
+	Dropped:  -0.5,
+	HalfLife: 3 * 24 * time.Hour,
+	Damping:  500,
+}
+
+// Filter narrows the subjects a trending list is built from.
+type Filter func(subject model.BangumiSubject) bool
+
+func ByType(subjectType int) Filter {
+	return func(subject model.BangumiSubject) bool {
+		return subject.Type == subjectType
+	}
+}
+
+func ByTag(name string) Filter {
+	return func(subject model.BangumiSubject) bool {
+		for _, tag := range subject.Tags {
+			if tag.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type Engine struct {
+	weights Weights
+	now     func() time.Time
+}
+
+func New(weights Weights) *Engine {
+	return &Engine{
+		weights: weights,
+		now:     time.Now,
+	}
+}
+
+// SetClock overrides the clock recency is measured against.
+func (e *Engine) SetClock(now func() time.Time) {
+	e.now = now
+}
+
+// Scores computes the trending score of every subject found in the snapshots.
+func (e *Engine) Scores(snapshots []model.FirestoreSubjectSnapshot) map[int]float64 {
+	bySubject := make(map[int][]model.FirestoreSubjectSnapshot)
+	for _, snapshot := range snapshots {
+		bySubject[snapshot.SubjectID] = append(bySubject[snapshot.SubjectID], snapshot)
+	}
+
+	scores := make(map[int]float64, len(bySubject))
+	for id, series := range bySubject {
+		scores[id] = e.Score(series)
+	}
+
+	return scores
+}
+
+// Score sums the weighted collection growth between consecutive snapshots of one subject,
+// relative to its collection total and decayed by the age of the later snapshot.
+func (e *Engine) Score(series []model.FirestoreSubjectSnapshot) float64 {
+	if len(series) < 2 {
+		return 0
+	}
+
+	sorted := make([]model.FirestoreSubjectSnapshot, len(series))
+	copy(sorted, series)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	now := e.now()
+	var score float64
+	for i := 1; i < len(sorted); i++ {
+		prev, curr := sorted[i-1].Collection, sorted[i].Collection
+
+		growth := e.weights.Doing*float64(curr.Doing-prev.Doing) +
+			e.weights.Wish*float64(curr.Wish-prev.Wish) +
+			e.weights.Collect*float64(curr.Collect-prev.Collect) +
+			e.weights.OnHold*float64(curr.OnHold-prev.OnHold) +
+			e.weights.Dropped*float64(curr.Dropped-prev.Dropped)
+
+		relative := growth / (float64(prev.Total()) + e.weights.Damping)
+		score += relative * e.decay(now.Sub(sorted[i].Timestamp))
+	}
+
+	return score
+}
+
+// Rank returns the subjects passing every filter ordered by trending score, highest first.
+// Subjects without positive growth are left out and limit <= 0 returns every subject.
+func (e *Engine) Rank(
+	subjects []model.BangumiSubject,
+	snapshots []model.FirestoreSubjectSnapshot,
+	limit int,
+	filters ...Filter,
+) []model.FirestoreSubject {
+	scores := e.Scores(snapshots)
+
+	type scored struct {
+		subject model.BangumiSubject
+		score   float64
+	}
+
+	var candidates []scored
+	for _, subject := range subjects {
+		if !matches(subject, filters) {
+			continue
+		}
+
+		score := scores[subject.ID]
+		if score <= 0 {
+			continue
+		}
+
+		candidates = append(candidates, scored{subject: subject, score: score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].subject.ID < candidates[j].subject.ID
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	res := make([]model.FirestoreSubject, len(candidates))
+	for i, c := range candidates {
+		res[i] = c.subject.ToFirestoreSubject()
+	}
+
+	return res
+}
+
+func (e *Engine) decay(age time.Duration) float64 {
+	if e.weights.HalfLife <= 0 || age <= 0 {
+		return 1
+	}
+
+	return math.Exp(-math.Ln2 * float64(age) / float64(e.weights.HalfLife))
+}
+
+func matches(subject model.BangumiSubject, filters []Filter) bool {
+	for _, filter := range filters {
+		if !filter(subject) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package trend
+
+import (
+	"github.com/bangumilite/bangumilite-component/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+func snapshot(id int, at time.Time, doing int, wish int) model.FirestoreSubjectSnapshot {
+	return model.FirestoreSubjectSnapshot{
+		SubjectID:  id,
+		Timestamp:  at,
+		Collection: model.BangumiCollection{Doing: doing, Wish: wish},
+	}
+}
+
+var _ = Describe("trend engine unit tests", func() {
+	var (
+		now    time.Time
+		engine *Engine
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)
+		engine = New(DefaultWeights)
+		engine.SetClock(func() time.Time { return now })
+	})
+
+	Describe("Score", func() {
+		It("returns zero for a single snapshot", func() {
+			Expect(engine.Score([]model.FirestoreSubjectSnapshot{snapshot(1, now, 10, 10)})).To(BeZero())
+		})
+
+		It("weights recent growth higher than old growth", func() {
+			recent := engine.Score([]model.FirestoreSubjectSnapshot{
+				snapshot(1, now.Add(-24*time.Hour), 1000, 0),
+				snapshot(1, now, 1100, 0),
+			})
+			old := engine.Score([]model.FirestoreSubjectSnapshot{
+				snapshot(2, now.Add(-7*24*time.Hour), 1000, 0),
+				snapshot(2, now.Add(-6*24*time.Hour), 1100, 0),
+			})
+
+			Expect(recent).To(BeNumerically(">", old))
+		})
+
+		It("damps the growth of small titles", func() {
+			small := engine.Score([]model.FirestoreSubjectSnapshot{
+				snapshot(1, now.Add(-24*time.Hour), 2, 0),
+				snapshot(1, now, 12, 0),
+			})
+			large := engine.Score([]model.FirestoreSubjectSnapshot{
+				snapshot(2, now.Add(-24*time.Hour), 2000, 0),
+				snapshot(2, now, 2400, 0),
+			})
+
+			Expect(large).To(BeNumerically(">", small))
+		})
+	})
+
+	Describe("Rank", func() {
+		subjects := []model.BangumiSubject{
+			{ID: 1, Type: 2, Tags: model.BangumiTags{{Name: "百合"}}},
+			{ID: 2, Type: 2},
+			{ID: 3, Type: 1},
+			{ID: 4, Type: 2},
+		}
+
+		var snapshots []model.FirestoreSubjectSnapshot
+
+		BeforeEach(func() {
+			snapshots = []model.FirestoreSubjectSnapshot{
+				snapshot(1, now.Add(-24*time.Hour), 100, 100),
+				snapshot(1, now, 200, 150),
+				snapshot(2, now.Add(-24*time.Hour), 100, 100),
+				snapshot(2, now, 400, 150),
+				snapshot(3, now.Add(-24*time.Hour), 100, 100),
+				snapshot(3, now, 900, 150),
+				snapshot(4, now.Add(-24*time.Hour), 100, 100),
+				snapshot(4, now, 90, 100),
+			}
+		})
+
+		It("ranks subjects by score and drops non-growing ones", func() {
+			got := engine.Rank(subjects, snapshots, 0)
+
+			Expect(got).To(HaveLen(3))
+			Expect(got[0].ID).To(Equal(3))
+			Expect(got[1].ID).To(Equal(2))
+			Expect(got[2].ID).To(Equal(1))
+		})
+
+		It("filters by type and tag", func() {
+			Expect(engine.Rank(subjects, snapshots, 0, ByType(2))).To(HaveLen(2))
+
+			got := engine.Rank(subjects, snapshots, 0, ByType(2), ByTag("百合"))
+			Expect(got).To(HaveLen(1))
+			Expect(got[0].ID).To(Equal(1))
+		})
+
+		It("applies the limit", func() {
+			Expect(engine.Rank(subjects, snapshots, 1)).To(HaveLen(1))
+		})
+	})
+})